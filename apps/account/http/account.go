@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/request"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+func (h *handler) CreateAccount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := account.NewDefaultAccount()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.account.CreateAccount(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 查询账号列表, 参数: page_size, page_number, keywords, vendor=ALI_CLOUD,TX_CLOUD
+func (h *handler) QueryAccount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	qs := r.URL.Query()
+	req := account.NewQueryAccountRequest()
+
+	var err error
+	if req.PageSize, err = getInt(qs.Get("page_size"), "page_size", req.PageSize); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.PageNumber, err = getInt(qs.Get("page_number"), "page_number", req.PageNumber); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	req.Keywords = qs.Get("keywords")
+	for _, item := range strings.Split(qs.Get("vendor"), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		v, err := host.ParseVendor(item)
+		if err != nil {
+			response.Failed(w, exception.NewBadRequest("vendor invalid, %s", err))
+			return
+		}
+		req.Vendor = append(req.Vendor, v)
+	}
+
+	set, err := h.account.QueryAccount(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
+
+// 查询账号详情, AccessKey和Secret脱敏后返回
+func (h *handler) DescribeAccount(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ins, err := h.account.DescribeAccount(r.Context(), account.NewDescribeAccountRequest(ps.ByName("id")))
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 部分更新账号, 为空的字段不修改
+func (h *handler) UpdateAccount(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req := account.NewUpdateAccountRequest(ps.ByName("id"))
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.account.UpdateAccount(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+func (h *handler) DeleteAccount(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ins, err := h.account.DeleteAccount(r.Context(), &account.DeleteAccountRequest{Id: ps.ByName("id")})
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 读取正整数参数, 为空时使用默认值
+func getInt(v, name string, defaultValue int) (int, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, exception.NewBadRequest("%s must be a positive integer, but got %s", name, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// Account 模块的 HTTP API 服务实例
+var API = handler{}
+
+type handler struct {
+	account account.Service
+	log     logger.Logger
+}
+
+func (h *handler) Init() {
+	h.log = zap.L().Named("ACCOUNT API")
+
+	if apps.Account == nil {
+		panic("dependence account service is nil")
+	}
+	h.account = apps.Account
+}
+
+func (h *handler) Registry(r *router.Router) {
+	r.POST("/accounts", permission.Required(policy.AccountWrite, h.CreateAccount))
+	r.GET("/accounts", permission.Required(policy.AccountRead, h.QueryAccount))
+	r.GET("/accounts/:id", permission.Required(policy.AccountRead, h.DescribeAccount))
+	r.PATCH("/accounts/:id", permission.Required(policy.AccountWrite, h.UpdateAccount))
+	r.DELETE("/accounts/:id", permission.Required(policy.AccountWrite, h.DeleteAccount))
+}
@@ -0,0 +1,199 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/sqlbuilder"
+	"github.com/infraboard/mcube/types/ftime"
+	"github.com/rs/xid"
+)
+
+const (
+	// MySQL 唯一索引冲突的错误码
+	errDuplicateEntry = 1062
+)
+
+func (i *impl) CreateAccount(ctx context.Context, ins *account.Account) (*account.Account, error) {
+	if err := ins.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate account error, %s", err)
+	}
+
+	ins.Id = xid.New().String()
+	ins.CreateAt = ftime.Now().Timestamp()
+	ins.UpdateAt = 0
+
+	ak, sk, err := i.seal(ins)
+	if err != nil {
+		return nil, err
+	}
+	_, err = i.db.ExecContext(ctx, insertAccountSQL,
+		ins.Id, ins.Vendor, ins.Name, ins.Description, ak, sk, ins.CreateAt, ins.UpdateAt,
+	)
+	if err != nil {
+		return nil, duplicateError(err, ins)
+	}
+
+	ins.Desensitize()
+	return ins, nil
+}
+
+func (i *impl) QueryAccount(ctx context.Context, req *account.QueryAccountRequest) (*account.Set, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query account request error, %s", err)
+	}
+
+	query := sqlbuilder.NewQuery(queryAccountSQL)
+	if req.Keywords != "" {
+		query.Where("name LIKE ?", "%"+req.Keywords+"%")
+	}
+	if len(req.Vendor) > 0 {
+		args := make([]interface{}, 0, len(req.Vendor))
+		for _, v := range req.Vendor {
+			args = append(args, v)
+		}
+		query.Where(fmt.Sprintf("vendor IN (%s)", strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")), args...)
+	}
+	query.Order("create_at").Desc().Limit(int64(req.Offset()), uint(req.PageSize))
+
+	sqlStr, args := query.BuildQuery()
+	i.log.Debugf("sql: %s, args: %v", sqlStr, args)
+
+	rows, err := i.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query account error, %s", err)
+	}
+	defer rows.Close()
+
+	set := account.NewSet()
+	for rows.Next() {
+		ins, err := i.scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		ins.Desensitize()
+		set.Add(ins)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	countStr, countArgs := query.BuildCount()
+	if err := i.db.QueryRowContext(ctx, countStr, countArgs...).Scan(&set.Total); err != nil {
+		return nil, fmt.Errorf("query account count error, %s", err)
+	}
+	return set, nil
+}
+
+func (i *impl) DescribeAccount(ctx context.Context, req *account.DescribeAccountRequest) (*account.Account, error) {
+	ins, err := i.describe(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !req.WithSecret {
+		ins.Desensitize()
+	}
+	return ins, nil
+}
+
+func (i *impl) UpdateAccount(ctx context.Context, req *account.UpdateAccountRequest) (*account.Account, error) {
+	ins, err := i.describe(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	ins.Patch(req)
+	ins.UpdateAt = ftime.Now().Timestamp()
+	if err := ins.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate account error, %s", err)
+	}
+
+	ak, sk, err := i.seal(ins)
+	if err != nil {
+		return nil, err
+	}
+	_, err = i.db.ExecContext(ctx, updateAccountSQL, ins.Name, ins.Description, ak, sk, ins.UpdateAt, ins.Id)
+	if err != nil {
+		return nil, duplicateError(err, ins)
+	}
+
+	ins.Desensitize()
+	return ins, nil
+}
+
+func (i *impl) DeleteAccount(ctx context.Context, req *account.DeleteAccountRequest) (*account.Account, error) {
+	ins, err := i.describe(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := i.db.ExecContext(ctx, deleteAccountSQL, ins.Id); err != nil {
+		return nil, fmt.Errorf("delete account %s error, %s", ins.Id, err)
+	}
+
+	ins.Desensitize()
+	return ins, nil
+}
+
+// 查询出解密后的账号
+func (i *impl) describe(ctx context.Context, id string) (*account.Account, error) {
+	query := sqlbuilder.NewQuery(queryAccountSQL).Where("id = ?", id)
+	sqlStr, args := query.BuildQuery()
+	i.log.Debugf("sql: %s, args: %v", sqlStr, args)
+
+	ins, err := i.scanAccount(i.db.QueryRowContext(ctx, sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewNotFound("account %s not found", id)
+		}
+		return nil, fmt.Errorf("query account error, %s", err)
+	}
+	return ins, nil
+}
+
+// 兼容 *sql.Row 和 *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// 按 queryAccountSQL 的字段顺序读取, 并解密AccessKey和Secret
+func (i *impl) scanAccount(row scanner) (*account.Account, error) {
+	ins := account.NewDefaultAccount()
+	err := row.Scan(&ins.Id, &ins.Vendor, &ins.Name, &ins.Description, &ins.AccessKey, &ins.Secret, &ins.CreateAt, &ins.UpdateAt)
+	if err != nil {
+		return nil, err
+	}
+	if ins.AccessKey, err = i.cipher.Decrypt(ins.AccessKey); err != nil {
+		return nil, fmt.Errorf("decrypt account %s access_key error, %s", ins.Id, err)
+	}
+	if ins.Secret, err = i.cipher.Decrypt(ins.Secret); err != nil {
+		return nil, fmt.Errorf("decrypt account %s secret error, %s", ins.Id, err)
+	}
+	return ins, nil
+}
+
+// 加密AccessKey和Secret
+func (i *impl) seal(ins *account.Account) (ak, sk string, err error) {
+	if ak, err = i.cipher.Encrypt(ins.AccessKey); err != nil {
+		return "", "", err
+	}
+	if sk, err = i.cipher.Encrypt(ins.Secret); err != nil {
+		return "", "", err
+	}
+	return ak, sk, nil
+}
+
+func duplicateError(err error, ins *account.Account) error {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) && e.Number == errDuplicateEntry {
+		return exception.NewConflict("account %s already exists", ins)
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package impl
+
+import (
+	"database/sql"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/secret"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+var Service *impl = &impl{}
+
+type impl struct {
+	log logger.Logger
+	db  *sql.DB
+	// AccessKey和Secret加密, 密钥来自App.Key
+	cipher *secret.Cipher
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("Account")
+
+	db, err := conf.C().MySQL.GetDB()
+	if err != nil {
+		return err
+	}
+	i.db = db
+
+	i.cipher, err = secret.NewCipher(conf.C().App.Key)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/secret"
+)
+
+// 使用新的密钥重新加密所有账号, 返回重新加密的账号数
+// 账号数量不多, 一次查询出来, 已经使用新密钥加密的账号同样可以处理
+func (i *impl) Rekey(ctx context.Context, to *secret.Cipher) (int, error) {
+	rows, err := i.db.QueryContext(ctx, queryAccountSQL)
+	if err != nil {
+		return 0, err
+	}
+	type item struct{ id, ak, sk string }
+	items := []item{}
+	for rows.Next() {
+		ins := account.NewDefaultAccount()
+		if err := rows.Scan(&ins.Id, &ins.Vendor, &ins.Name, &ins.Description, &ins.AccessKey, &ins.Secret, &ins.CreateAt, &ins.UpdateAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		items = append(items, item{ins.Id, ins.AccessKey, ins.Secret})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, it := range items {
+		ak, err := i.reseal(to, it.ak)
+		if err != nil {
+			return count, fmt.Errorf("account %s access_key, %s", it.id, err)
+		}
+		sk, err := i.reseal(to, it.sk)
+		if err != nil {
+			return count, fmt.Errorf("account %s secret, %s", it.id, err)
+		}
+		if _, err := i.db.ExecContext(ctx, updateAccountSecretSQL, ak, sk, it.id); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// 先使用当前密钥解密, 失败时说明已经使用新密钥加密过
+func (i *impl) reseal(to *secret.Cipher, v string) (string, error) {
+	plain, err := i.cipher.Decrypt(v)
+	if err != nil {
+		if plain, err = to.Decrypt(v); err != nil {
+			return "", err
+		}
+	}
+	return to.Encrypt(plain)
+}
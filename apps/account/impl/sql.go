@@ -0,0 +1,13 @@
+package impl
+
+const (
+	insertAccountSQL = `INSERT INTO cloud_account (id,vendor,name,description,access_key,secret,create_at,update_at) VALUES (?,?,?,?,?,?,?,?)`
+
+	queryAccountSQL = `SELECT id,vendor,name,description,access_key,secret,create_at,update_at FROM cloud_account`
+
+	updateAccountSQL = `UPDATE cloud_account SET name=?,description=?,access_key=?,secret=?,update_at=? WHERE id=?`
+
+	deleteAccountSQL = `DELETE FROM cloud_account WHERE id=?`
+
+	updateAccountSecretSQL = `UPDATE cloud_account SET access_key=?,secret=? WHERE id=?`
+)
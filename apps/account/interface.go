@@ -0,0 +1,70 @@
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 云账号管理, AccessKey和Secret加密存储, 查询时默认脱敏
+type Service interface {
+	CreateAccount(context.Context, *Account) (*Account, error)
+	QueryAccount(context.Context, *QueryAccountRequest) (*Set, error)
+	DescribeAccount(context.Context, *DescribeAccountRequest) (*Account, error)
+	UpdateAccount(context.Context, *UpdateAccountRequest) (*Account, error)
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*Account, error)
+}
+
+func NewQueryAccountRequest() *QueryAccountRequest {
+	return &QueryAccountRequest{
+		PageSize:   20,
+		PageNumber: 1,
+	}
+}
+
+type QueryAccountRequest struct {
+	PageSize   int
+	PageNumber int
+	// 按名称模糊匹配
+	Keywords string
+	Vendor   []host.Vendor
+}
+
+func (req *QueryAccountRequest) Validate() error {
+	if req.PageSize <= 0 || req.PageNumber <= 0 {
+		return fmt.Errorf("page_size and page_number must be positive")
+	}
+	return nil
+}
+
+func (req *QueryAccountRequest) Offset() int {
+	return (req.PageNumber - 1) * req.PageSize
+}
+
+func NewDescribeAccountRequest(id string) *DescribeAccountRequest {
+	return &DescribeAccountRequest{Id: id}
+}
+
+type DescribeAccountRequest struct {
+	Id string
+	// 返回明文的AccessKey和Secret, 只用于内部调用(比如同步), 不对外暴露
+	WithSecret bool
+}
+
+func NewUpdateAccountRequest(id string) *UpdateAccountRequest {
+	return &UpdateAccountRequest{Id: id}
+}
+
+// 部分更新, 为空的字段不修改
+type UpdateAccountRequest struct {
+	Id          string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AccessKey   string `json:"access_key"`
+	Secret      string `json:"secret"`
+}
+
+type DeleteAccountRequest struct {
+	Id string
+}
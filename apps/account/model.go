@@ -0,0 +1,87 @@
+package account
+
+import (
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate = validator.New()
+)
+
+const (
+	// 脱敏后的Secret
+	maskedSecret = "******"
+)
+
+func NewDefaultAccount() *Account {
+	return &Account{}
+}
+
+// 云厂商的账号, 同步任务和主机通过Id引用
+type Account struct {
+	Id     string      `json:"id"`
+	Vendor host.Vendor `json:"vendor"`
+	// 同一个厂商下唯一
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+	AccessKey   string `json:"access_key" validate:"required"`
+	Secret      string `json:"secret" validate:"required"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+}
+
+func (a *Account) Validate() error {
+	return validate.Struct(a)
+}
+
+// 合并更新的字段
+func (a *Account) Patch(req *UpdateAccountRequest) {
+	if req.Name != "" {
+		a.Name = req.Name
+	}
+	if req.Description != "" {
+		a.Description = req.Description
+	}
+	if req.AccessKey != "" {
+		a.AccessKey = req.AccessKey
+	}
+	if req.Secret != "" {
+		a.Secret = req.Secret
+	}
+}
+
+// 脱敏, AccessKey只保留首尾4位, Secret完全隐藏
+func (a *Account) Desensitize() {
+	if n := len(a.AccessKey); n > 8 {
+		a.AccessKey = a.AccessKey[:4] + strings.Repeat("*", n-8) + a.AccessKey[n-4:]
+	} else if n > 0 {
+		a.AccessKey = strings.Repeat("*", n)
+	}
+	if a.Secret != "" {
+		a.Secret = maskedSecret
+	}
+}
+
+func (a *Account) String() string {
+	return fmt.Sprintf("%s/%s", a.Vendor, a.Name)
+}
+
+func NewSet() *Set {
+	return &Set{
+		Items: []*Account{},
+	}
+}
+
+type Set struct {
+	Total int64      `json:"total"`
+	Items []*Account `json:"items"`
+}
+
+func (s *Set) Add(item *Account) {
+	s.Items = append(s.Items, item)
+}
@@ -0,0 +1,23 @@
+package account_test
+
+import (
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDesensitize(t *testing.T) {
+	should := assert.New(t)
+
+	ins := &account.Account{AccessKey: "LTAI5tExampleKey01", Secret: "secret"}
+	ins.Desensitize()
+	should.Equal("LTAI**********ey01", ins.AccessKey)
+	should.Equal("******", ins.Secret)
+
+	short := &account.Account{AccessKey: "ak01"}
+	short.Desensitize()
+	should.Equal("****", short.AccessKey)
+	should.Equal("", short.Secret)
+}
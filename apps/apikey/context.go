@@ -0,0 +1,15 @@
+package apikey
+
+import "context"
+
+type contextKey struct{}
+
+// 把通过认证的API Key放入上下文, 用于权限检查时限制授权范围
+func WithAPIKey(ctx context.Context, k *APIKey) context.Context {
+	return context.WithValue(ctx, contextKey{}, k)
+}
+
+func FromContext(ctx context.Context) *APIKey {
+	k, _ := ctx.Value(contextKey{}).(*APIKey)
+	return k
+}
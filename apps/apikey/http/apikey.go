@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/request"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// 给当前用户创建Key, 明文Key只在响应中返回一次
+func (h *handler) CreateAPIKey(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// 不允许用Key创建新的Key, 避免泄露的Key自我续期
+	if apikey.FromContext(r.Context()) != nil {
+		response.Failed(w, exception.NewPermissionDeny("api key can not be used to create api key"))
+		return
+	}
+
+	req := apikey.NewCreateAPIKeyRequest()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	req.Owner = user.FromContext(r.Context())
+	if req.Owner == nil {
+		response.Failed(w, exception.NewBadRequest("api key requires a login user as owner"))
+		return
+	}
+	// 通过Key认证时需要查询所属用户, 外部身份提供方(JWT)的用户不能创建
+	if _, err := h.user.DescribeUser(r.Context(), &user.DescribeUserRequest{Id: req.Owner.Id}); err != nil {
+		if exception.IsNotFoundError(err) {
+			err = exception.NewBadRequest("api key owner must be a local user")
+		}
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.apikey.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 查询Key列表, 参数: page_size, page_number, user_id, with_revoked=true
+// 非管理员只能查询自己的Key, 忽略user_id参数
+func (h *handler) QueryAPIKey(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	qs := r.URL.Query()
+	req := apikey.NewQueryAPIKeyRequest()
+
+	var err error
+	if req.PageSize, err = getInt(qs.Get("page_size"), "page_size", req.PageSize); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.PageNumber, err = getInt(qs.Get("page_number"), "page_number", req.PageNumber); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	req.UserId = qs.Get("user_id")
+	if u := user.FromContext(r.Context()); u != nil && u.Role != user.RoleAdmin {
+		req.UserId = u.Id
+	}
+	req.WithRevoked = qs.Get("with_revoked") == "true"
+
+	set, err := h.apikey.QueryAPIKey(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
+
+// 吊销Key, 非管理员只能吊销自己的Key
+func (h *handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req := apikey.NewRevokeAPIKeyRequest(ps.ByName("id"))
+	if u := user.FromContext(r.Context()); u != nil && u.Role != user.RoleAdmin {
+		req.UserId = u.Id
+	}
+
+	ins, err := h.apikey.RevokeAPIKey(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+func getInt(v, name string, defaultValue int) (int, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, exception.NewBadRequest("%s must be a positive integer, but got %s", name, v)
+	}
+	return n, nil
+}
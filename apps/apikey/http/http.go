@@ -0,0 +1,43 @@
+package http
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// APIKey 模块的 HTTP API 服务实例
+var API = handler{}
+
+type handler struct {
+	apikey apikey.Service
+	user   user.Service
+	log    logger.Logger
+}
+
+func (h *handler) Init() {
+	h.log = zap.L().Named("APIKEY API")
+
+	if apps.APIKey == nil {
+		panic("dependence api key service is nil")
+	}
+	h.apikey = apps.APIKey
+
+	if apps.User == nil {
+		panic("dependence user service is nil")
+	}
+	h.user = apps.User
+}
+
+func (h *handler) Registry(r *router.Router) {
+	r.POST("/apikeys", permission.Required(policy.APIKeyWrite, h.CreateAPIKey))
+	r.GET("/apikeys", permission.Required(policy.APIKeyRead, h.QueryAPIKey))
+	// 吊销, 记录保留
+	r.DELETE("/apikeys/:id", permission.Required(policy.APIKeyWrite, h.RevokeAPIKey))
+}
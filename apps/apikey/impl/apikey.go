@@ -0,0 +1,99 @@
+package impl
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+	"github.com/rs/xid"
+)
+
+func (i *impl) CreateAPIKey(ctx context.Context, req *apikey.CreateAPIKeyRequest) (*apikey.APIKey, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate create api key request error, %s", err)
+	}
+
+	now := ftime.Now().Timestamp()
+	if req.ExpireAt != 0 && req.ExpireAt <= now {
+		return nil, exception.NewBadRequest("expire_at must be in the future")
+	}
+
+	ins := &apikey.APIKey{
+		Id:          xid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Scopes:      req.Scopes,
+		UserId:      req.Owner.Id,
+		Username:    req.Owner.Username,
+		CreateAt:    now,
+		ExpireAt:    req.ExpireAt,
+	}
+	if err := ins.GenerateKey(); err != nil {
+		return nil, err
+	}
+	if err := i.store.SaveAPIKey(ctx, ins); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (i *impl) QueryAPIKey(ctx context.Context, req *apikey.QueryAPIKeyRequest) (*apikey.Set, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query api key request error, %s", err)
+	}
+	return i.store.QueryAPIKey(ctx, req)
+}
+
+func (i *impl) RevokeAPIKey(ctx context.Context, req *apikey.RevokeAPIKeyRequest) (*apikey.APIKey, error) {
+	ins, err := i.store.GetAPIKey(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	// 其他用户的Key按不存在处理
+	if req.UserId != "" && ins.UserId != req.UserId {
+		return nil, exception.NewNotFound("api key not found")
+	}
+	// 重复吊销保持第一次的吊销时间
+	if ins.RevokeAt > 0 {
+		return ins, nil
+	}
+
+	ins.RevokeAt = ftime.Now().Timestamp()
+	if err := i.store.RevokeAPIKey(ctx, ins.Id, ins.RevokeAt); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (i *impl) ValidateAPIKey(ctx context.Context, req *apikey.ValidateAPIKeyRequest) (*apikey.APIKey, error) {
+	if req.Key == "" {
+		return nil, exception.NewUnauthorized("api key required")
+	}
+
+	ins, err := i.store.GetAPIKeyByHash(ctx, apikey.HashKey(req.Key))
+	if err != nil {
+		if exception.IsNotFoundError(err) {
+			return nil, exception.NewUnauthorized("api key invalid")
+		}
+		return nil, err
+	}
+
+	now := ftime.Now().Timestamp()
+	if ins.RevokeAt > 0 {
+		return nil, exception.NewUnauthorized("api key revoked")
+	}
+	if !ins.Active(now) {
+		return nil, exception.NewUnauthorized("api key expired")
+	}
+
+	if now-ins.LastUsedAt >= i.lastUsedInterval.Milliseconds() {
+		ins.LastUsedAt = now
+		// 记录失败不影响本次请求
+		if err := i.store.UpdateLastUsed(ctx, ins.Id, now); err != nil {
+			i.log.Warnf("update api key %s last used error, %s", ins.Id, err)
+		}
+	}
+	return ins, nil
+}
@@ -0,0 +1,66 @@
+package impl_test
+
+import (
+	"context"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	cfg := conf.NewDefaultConfig()
+	cfg.App.Storage = conf.MemoryStorage
+	conf.SetGlobalConfig(cfg)
+	if !should.NoError(impl.Service.Init()) {
+		return
+	}
+
+	req := apikey.NewCreateAPIKeyRequest()
+	req.Name = "ci"
+	req.Scopes = []policy.Permission{policy.HostRead}
+	req.Owner = &user.User{Id: "u1", Username: "admin"}
+	ins, err := impl.Service.CreateAPIKey(ctx, req)
+	if !should.NoError(err) {
+		return
+	}
+	should.NotEmpty(ins.Key)
+	should.Equal(apikey.HashKey(ins.Key), ins.Hash)
+
+	k, err := impl.Service.ValidateAPIKey(ctx, &apikey.ValidateAPIKeyRequest{Key: ins.Key})
+	if should.NoError(err) {
+		should.Equal(ins.Id, k.Id)
+		should.Empty(k.Key)
+		should.NotZero(k.LastUsedAt)
+	}
+
+	set, err := impl.Service.QueryAPIKey(ctx, apikey.NewQueryAPIKeyRequest())
+	if should.NoError(err) {
+		should.Equal(int64(1), set.Total)
+	}
+
+	// 其他用户不能吊销
+	revoke := apikey.NewRevokeAPIKeyRequest(ins.Id)
+	revoke.UserId = "u2"
+	_, err = impl.Service.RevokeAPIKey(ctx, revoke)
+	should.True(exception.IsNotFoundError(err))
+
+	revoke.UserId = "u1"
+	_, err = impl.Service.RevokeAPIKey(ctx, revoke)
+	should.NoError(err)
+	_, err = impl.Service.ValidateAPIKey(ctx, &apikey.ValidateAPIKeyRequest{Key: ins.Key})
+	should.Equal(exception.Unauthorized, err.(exception.APIException).ErrorCode())
+
+	req.Scopes = []policy.Permission{"host:fly"}
+	_, err = impl.Service.CreateAPIKey(ctx, req)
+	should.Error(err)
+}
@@ -0,0 +1,41 @@
+package impl
+
+import (
+	"fmt"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+var Service *impl = &impl{}
+
+type impl struct {
+	log   logger.Logger
+	store apikey.Store
+	// 最后使用时间的更新间隔, 避免每个请求都写一次存储
+	lastUsedInterval time.Duration
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("APIKey")
+	i.lastUsedInterval = time.Minute
+
+	// 和用户数据使用相同的存储
+	switch conf.C().App.Storage {
+	case conf.MySQLStorage, "":
+		db, err := conf.C().MySQL.GetDB()
+		if err != nil {
+			return err
+		}
+		i.store = newMySQLStore(db)
+	case conf.MemoryStorage:
+		i.store = newMemoryStore()
+	default:
+		return fmt.Errorf("unknown storage type %s", conf.C().App.Storage)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+const (
+	insertAPIKeySQL = `INSERT INTO api_key (
+		id, name, description, prefix, key_hash, scopes, user_id, username, create_at, expire_at, last_used_at, revoke_at
+	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`
+	queryAPIKeySQL = `SELECT id, name, description, prefix, key_hash, scopes, user_id, username,
+		create_at, expire_at, last_used_at, revoke_at FROM api_key`
+	countAPIKeySQL    = `SELECT COUNT(*) FROM api_key`
+	revokeAPIKeySQL   = `UPDATE api_key SET revoke_at=? WHERE id=? AND revoke_at=0`
+	updateLastUsedSQL = `UPDATE api_key SET last_used_at=? WHERE id=?`
+)
+
+func newMySQLStore(db *sql.DB) *mysqlStore {
+	return &mysqlStore{db: db}
+}
+
+type mysqlStore struct {
+	db *sql.DB
+}
+
+func (s *mysqlStore) SaveAPIKey(ctx context.Context, k *apikey.APIKey) error {
+	_, err := s.db.ExecContext(ctx, insertAPIKeySQL,
+		k.Id, k.Name, k.Description, k.Prefix, k.Hash, joinScopes(k.Scopes), k.UserId, k.Username,
+		k.CreateAt, k.ExpireAt, k.LastUsedAt, k.RevokeAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save api key error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) QueryAPIKey(ctx context.Context, req *apikey.QueryAPIKeyRequest) (*apikey.Set, error) {
+	query := sqlbuilder.NewQuery(queryAPIKeySQL)
+	count := sqlbuilder.NewQuery(countAPIKeySQL)
+	if req.UserId != "" {
+		query.Where("user_id = ?", req.UserId)
+		count.Where("user_id = ?", req.UserId)
+	}
+	if !req.WithRevoked {
+		query.Where("revoke_at = 0")
+		count.Where("revoke_at = 0")
+	}
+	query.Order("create_at").Desc().Limit(int64(req.Offset()), uint(req.PageSize))
+
+	sqlStr, args := query.BuildQuery()
+	rows, err := s.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query api key error, %s", err)
+	}
+	defer rows.Close()
+
+	set := apikey.NewSet()
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		set.Add(k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args = count.BuildQuery()
+	if err := s.db.QueryRowContext(ctx, sqlStr, args...).Scan(&set.Total); err != nil {
+		return nil, fmt.Errorf("count api key error, %s", err)
+	}
+	return set, nil
+}
+
+func (s *mysqlStore) GetAPIKey(ctx context.Context, id string) (*apikey.APIKey, error) {
+	return s.get(ctx, "id = ?", id)
+}
+
+func (s *mysqlStore) GetAPIKeyByHash(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	return s.get(ctx, "key_hash = ?", hash)
+}
+
+func (s *mysqlStore) get(ctx context.Context, where string, arg interface{}) (*apikey.APIKey, error) {
+	query := sqlbuilder.NewQuery(queryAPIKeySQL)
+	query.Where(where, arg)
+	sqlStr, args := query.BuildQuery()
+
+	k, err := scanAPIKey(s.db.QueryRowContext(ctx, sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewNotFound("api key not found")
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (s *mysqlStore) RevokeAPIKey(ctx context.Context, id string, revokeAt int64) error {
+	if _, err := s.db.ExecContext(ctx, revokeAPIKeySQL, revokeAt, id); err != nil {
+		return fmt.Errorf("revoke api key error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) UpdateLastUsed(ctx context.Context, id string, lastUsedAt int64) error {
+	if _, err := s.db.ExecContext(ctx, updateLastUsedSQL, lastUsedAt, id); err != nil {
+		return fmt.Errorf("update api key last used error, %s", err)
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row scanner) (*apikey.APIKey, error) {
+	var (
+		k      = &apikey.APIKey{}
+		scopes string
+	)
+	err := row.Scan(&k.Id, &k.Name, &k.Description, &k.Prefix, &k.Hash, &scopes, &k.UserId, &k.Username,
+		&k.CreateAt, &k.ExpireAt, &k.LastUsedAt, &k.RevokeAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan api key error, %s", err)
+	}
+	k.Scopes = splitScopes(scopes)
+	return k, nil
+}
+
+// 授权范围使用逗号分隔保存
+func joinScopes(scopes []policy.Permission) string {
+	items := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		items = append(items, string(s))
+	}
+	return strings.Join(items, ",")
+}
+
+func splitScopes(s string) []policy.Permission {
+	scopes := []policy.Permission{}
+	for _, item := range strings.Split(s, ",") {
+		if item != "" {
+			scopes = append(scopes, policy.Permission(item))
+		}
+	}
+	return scopes
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		keys: map[string]*apikey.APIKey{},
+	}
+}
+
+// 基于内存的存储, 服务重启后Key全部失效
+type memoryStore struct {
+	lock sync.Mutex
+	keys map[string]*apikey.APIKey
+}
+
+func (s *memoryStore) SaveAPIKey(ctx context.Context, k *apikey.APIKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cp := *k
+	cp.Key = ""
+	s.keys[k.Id] = &cp
+	return nil
+}
+
+func (s *memoryStore) QueryAPIKey(ctx context.Context, req *apikey.QueryAPIKeyRequest) (*apikey.Set, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	items := []*apikey.APIKey{}
+	for _, k := range s.keys {
+		if req.UserId != "" && k.UserId != req.UserId {
+			continue
+		}
+		if !req.WithRevoked && k.RevokeAt > 0 {
+			continue
+		}
+		cp := *k
+		items = append(items, &cp)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreateAt != items[j].CreateAt {
+			return items[i].CreateAt > items[j].CreateAt
+		}
+		return items[i].Id > items[j].Id
+	})
+
+	set := apikey.NewSet()
+	set.Total = int64(len(items))
+	for idx := req.Offset(); idx < len(items) && idx < req.Offset()+req.PageSize; idx++ {
+		set.Add(items[idx])
+	}
+	return set, nil
+}
+
+func (s *memoryStore) GetAPIKey(ctx context.Context, id string) (*apikey.APIKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, exception.NewNotFound("api key not found")
+	}
+	cp := *k
+	return &cp, nil
+}
+
+func (s *memoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, k := range s.keys {
+		if k.Hash == hash {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, exception.NewNotFound("api key not found")
+}
+
+func (s *memoryStore) RevokeAPIKey(ctx context.Context, id string, revokeAt int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if k, ok := s.keys[id]; ok && k.RevokeAt == 0 {
+		k.RevokeAt = revokeAt
+	}
+	return nil
+}
+
+func (s *memoryStore) UpdateLastUsed(ctx context.Context, id string, lastUsedAt int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if k, ok := s.keys[id]; ok {
+		k.LastUsedAt = lastUsedAt
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+)
+
+// API Key 管理, 给CI和同步机器人等自动化客户端使用
+type Service interface {
+	// 创建Key, 返回的对象中包含明文Key, 之后无法再次获取
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*APIKey, error)
+	QueryAPIKey(context.Context, *QueryAPIKeyRequest) (*Set, error)
+	// 吊销后Key立即失效, 记录保留用于审计
+	RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*APIKey, error)
+	// 校验Key, 返回Key的信息, Key无效、过期或者吊销时返回401异常
+	ValidateAPIKey(context.Context, *ValidateAPIKeyRequest) (*APIKey, error)
+}
+
+func NewCreateAPIKeyRequest() *CreateAPIKeyRequest {
+	return &CreateAPIKeyRequest{}
+}
+
+type CreateAPIKeyRequest struct {
+	Name        string              `json:"name" validate:"required,lte=120"`
+	Description string              `json:"description"`
+	Scopes      []policy.Permission `json:"scopes" validate:"required,min=1"`
+	// 过期时间, 毫秒时间戳, 0表示永不过期
+	ExpireAt int64 `json:"expire_at"`
+	// 所属用户, 取自当前登录的用户
+	Owner *user.User `json:"-"`
+}
+
+func (req *CreateAPIKeyRequest) Validate() error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	if req.Owner == nil {
+		return fmt.Errorf("api key owner required")
+	}
+	for _, s := range req.Scopes {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewQueryAPIKeyRequest() *QueryAPIKeyRequest {
+	return &QueryAPIKeyRequest{
+		PageSize:   20,
+		PageNumber: 1,
+	}
+}
+
+type QueryAPIKeyRequest struct {
+	PageSize   int
+	PageNumber int
+	// 按所属用户过滤
+	UserId string
+	// 是否包含已经吊销的Key
+	WithRevoked bool
+}
+
+func (req *QueryAPIKeyRequest) Validate() error {
+	if req.PageSize <= 0 || req.PageNumber <= 0 {
+		return fmt.Errorf("page_size and page_number must be positive")
+	}
+	return nil
+}
+
+func (req *QueryAPIKeyRequest) Offset() int {
+	return (req.PageNumber - 1) * req.PageSize
+}
+
+func NewRevokeAPIKeyRequest(id string) *RevokeAPIKeyRequest {
+	return &RevokeAPIKeyRequest{Id: id}
+}
+
+type RevokeAPIKeyRequest struct {
+	Id string
+	// 只能吊销该用户的Key, 为空时不限制(管理员)
+	UserId string
+}
+
+type ValidateAPIKeyRequest struct {
+	Key string
+}
+
+// API Key 的存储
+type Store interface {
+	SaveAPIKey(context.Context, *APIKey) error
+	QueryAPIKey(context.Context, *QueryAPIKeyRequest) (*Set, error)
+	// Key不存在时返回NotFound异常
+	GetAPIKey(ctx context.Context, id string) (*APIKey, error)
+	// 按摘要查询, Key不存在时返回NotFound异常
+	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, revokeAt int64) error
+	UpdateLastUsed(ctx context.Context, id string, lastUsedAt int64) error
+}
@@ -0,0 +1,98 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate = validator.New()
+)
+
+const (
+	// 通过该Header携带API Key
+	HeaderName = "X-API-Key"
+	// Key的固定前缀, 方便在日志和代码仓库中识别泄露的Key
+	keyPrefix = "rak_"
+	// 列表中展示的Key前缀长度
+	displayLength = 12
+)
+
+// 自动化客户端使用的长期凭证, 只保存Key的摘要
+type APIKey struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Key的前几位, 用于识别是哪个Key
+	Prefix string `json:"prefix"`
+	// 明文Key只在创建时返回一次
+	Key string `json:"key,omitempty"`
+	// Key的sha256摘要, 不对外返回
+	Hash string `json:"-"`
+	// 授权范围, 实际权限是所属用户角色权限和授权范围的交集
+	Scopes []policy.Permission `json:"scopes"`
+	// 所属用户, 即创建人
+	UserId   string `json:"user_id"`
+	Username string `json:"username"`
+	CreateAt int64  `json:"create_at"`
+	// 过期时间, 0表示永不过期
+	ExpireAt int64 `json:"expire_at"`
+	// 最后一次使用的时间, 按分钟粒度更新
+	LastUsedAt int64 `json:"last_used_at"`
+	// 吊销时间, 0表示未吊销
+	RevokeAt int64 `json:"revoke_at"`
+}
+
+// Key是否可用
+func (k *APIKey) Active(now int64) bool {
+	if k.RevokeAt > 0 {
+		return false
+	}
+	return k.ExpireAt == 0 || k.ExpireAt > now
+}
+
+// 生成随机Key, 同时计算摘要和展示前缀
+func (k *APIKey) GenerateKey() error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	k.Key = keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	k.Prefix = k.Key[:displayLength]
+	k.Hash = HashKey(k.Key)
+	return nil
+}
+
+// Key的摘要, Key本身是高熵的随机串, 不需要加盐
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// 从 X-API-Key Header 中读取Key
+func GetKeyFromHTTP(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(HeaderName))
+}
+
+func NewSet() *Set {
+	return &Set{
+		Items: []*APIKey{},
+	}
+}
+
+type Set struct {
+	Total int64     `json:"total"`
+	Items []*APIKey `json:"items"`
+}
+
+func (s *Set) Add(item *APIKey) {
+	s.Items = append(s.Items, item)
+}
@@ -0,0 +1,52 @@
+package host
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// 游标分页的位置, 基于(create_at, id)倒序
+type Cursor struct {
+	CreateAt int64  `json:"c"`
+	Id       string `json:"i"`
+}
+
+// 以主机的位置生成游标
+func NewCursor(h *Host) *Cursor {
+	return &Cursor{
+		CreateAt: h.CreateAt,
+		Id:       h.Id,
+	}
+}
+
+// 编码成对外不透明的字符串
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// 判断主机是否排在游标之后
+func (c *Cursor) Before(h *Host) bool {
+	if h.CreateAt == c.CreateAt {
+		return h.Id < c.Id
+	}
+	return h.CreateAt < c.CreateAt
+}
+
+// 解析游标字符串
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cursor format error, %s", err)
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cursor format error, %s", err)
+	}
+	if c.Id == "" {
+		return nil, fmt.Errorf("cursor format error, id required")
+	}
+	return c, nil
+}
@@ -0,0 +1,43 @@
+package host
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 版本号不匹配, 对应HTTP 412
+func NewPreconditionFailed(format string, a ...interface{}) exception.APIException {
+	return exception.NewAPIException(exception.GlobalNamespace.String(), http.StatusPreconditionFailed, "", format, a...)
+}
+
+// 主机的ETag, 使用乐观锁版本号生成
+func (h *Host) ETag() string {
+	return fmt.Sprintf(`"%d"`, h.Version)
+}
+
+// 解析If-Match Header, 返回期望的版本号, 0 表示不做校验(Header为空或者为*)
+func ParseIfMatch(v string) (int64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+
+	v = strings.TrimPrefix(v, "W/")
+	version, err := strconv.ParseInt(strings.Trim(v, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match %s is not a valid host etag", v)
+	}
+	return version, nil
+}
+
+// 校验期望的版本号, expected 为0时不校验
+func CheckVersion(ins *Host, expected int64) error {
+	if expected > 0 && ins.Version != expected {
+		return NewPreconditionFailed("host %s version is %d, but If-Match %d", ins.Id, ins.Version, expected)
+	}
+	return nil
+}
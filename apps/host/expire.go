@@ -0,0 +1,27 @@
+package host
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 解析时间范围, 除了Go的时间格式(12h, 30m)外, 还支持按天: 7d
+func ParseWithin(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if strings.HasSuffix(s, "d") {
+		var days int
+		days, err = strconv.Atoi(strings.TrimSuffix(s, "d"))
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("within %s invalid, must be a positive duration like 7d or 12h", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 列的数据类型, 不同格式按类型输出
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindNumber
+	// 13位时间戳, 表格中输出为日期时间
+	KindTime
+	// 标签, 表格中输出为 k1=v1,k2=v2
+	KindTags
+)
+
+// 导出的一列, 名称和主机的JSON字段名相同
+type Column struct {
+	Name  string
+	Kind  Kind
+	Value func(*host.Host) interface{}
+}
+
+// 表格中单元格的文本
+func (c *Column) Text(h *host.Host) string {
+	v := c.Value(h)
+	switch c.Kind {
+	case KindTime:
+		ts := v.(int64)
+		if ts == 0 {
+			return ""
+		}
+		return time.UnixMilli(ts).Format("2006-01-02 15:04:05")
+	case KindTags:
+		tags := v.(map[string]string)
+		items := make([]string, 0, len(tags))
+		for k, tv := range tags {
+			items = append(items, k+"="+tv)
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+var (
+	// 所有可以导出的列, 也是默认的导出顺序
+	columns = []*Column{
+		{"id", KindString, func(h *host.Host) interface{} { return h.Id }},
+		{"namespace", KindString, func(h *host.Host) interface{} { return h.Namespace }},
+		{"vendor", KindString, func(h *host.Host) interface{} { return h.Vendor.String() }},
+		{"region", KindString, func(h *host.Host) interface{} { return h.Region }},
+		{"zone", KindString, func(h *host.Host) interface{} { return h.Zone }},
+		{"create_at", KindTime, func(h *host.Host) interface{} { return h.CreateAt }},
+		{"expire_at", KindTime, func(h *host.Host) interface{} { return h.ExpireAt }},
+		{"category", KindString, func(h *host.Host) interface{} { return h.Category }},
+		{"type", KindString, func(h *host.Host) interface{} { return h.Type }},
+		{"instance_id", KindString, func(h *host.Host) interface{} { return h.InstanceId }},
+		{"name", KindString, func(h *host.Host) interface{} { return h.Name }},
+		{"description", KindString, func(h *host.Host) interface{} { return h.Description }},
+		{"status", KindString, func(h *host.Host) interface{} { return h.Status }},
+		{"tags", KindTags, func(h *host.Host) interface{} { return h.Tags }},
+		{"update_at", KindTime, func(h *host.Host) interface{} { return h.UpdateAt }},
+		{"sync_at", KindTime, func(h *host.Host) interface{} { return h.SyncAt }},
+		{"sync_account", KindString, func(h *host.Host) interface{} { return h.SyncAccount }},
+		{"public_ip", KindString, func(h *host.Host) interface{} { return h.PublicIP }},
+		{"private_ip", KindString, func(h *host.Host) interface{} { return h.PrivateIP }},
+		{"pay_type", KindString, func(h *host.Host) interface{} { return h.PayType }},
+		{"cpu", KindNumber, func(h *host.Host) interface{} { return h.CPU }},
+		{"memory", KindNumber, func(h *host.Host) interface{} { return h.Memory }},
+		{"gpu_amount", KindNumber, func(h *host.Host) interface{} { return h.GPUAmount }},
+		{"gpu_spec", KindString, func(h *host.Host) interface{} { return h.GPUSpec }},
+		{"os_type", KindString, func(h *host.Host) interface{} { return h.OSType }},
+		{"os_name", KindString, func(h *host.Host) interface{} { return h.OSName }},
+		{"serial_number", KindString, func(h *host.Host) interface{} { return h.SerialNumber }},
+		{"image_id", KindString, func(h *host.Host) interface{} { return h.ImageID }},
+		{"internet_max_bandwidth_out", KindNumber, func(h *host.Host) interface{} { return h.InternetMaxBandwidthOut }},
+		{"internet_max_bandwidth_in", KindNumber, func(h *host.Host) interface{} { return h.InternetMaxBandwidthIn }},
+		{"key_pair_name", KindString, func(h *host.Host) interface{} { return h.KeyPairName }},
+		{"security_groups", KindString, func(h *host.Host) interface{} { return h.SecurityGroups }},
+	}
+)
+
+// 解析导出的列, 多个列使用逗号分隔, 为空时导出所有列
+func ParseColumns(s string) ([]*Column, error) {
+	if strings.TrimSpace(s) == "" {
+		return columns, nil
+	}
+
+	index := make(map[string]*Column, len(columns))
+	for _, c := range columns {
+		index[c.Name] = c
+	}
+
+	selected := []*Column{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		c, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s can not be exported", name)
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
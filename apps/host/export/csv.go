@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+func newCSVWriter(w io.Writer, cols []*Column) (*csvWriter, error) {
+	// 写入BOM, Excel 打开时才能正确识别UTF-8编码
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return nil, err
+	}
+
+	cw := &csvWriter{out: w, w: csv.NewWriter(w), cols: cols}
+	header := make([]string, 0, len(cols))
+	for _, c := range cols {
+		header = append(header, c.Name)
+	}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+type csvWriter struct {
+	out  io.Writer
+	w    *csv.Writer
+	cols []*Column
+}
+
+func (cw *csvWriter) WriteHost(h *host.Host) error {
+	record := make([]string, 0, len(cw.cols))
+	for _, c := range cw.cols {
+		record = append(record, c.Text(h))
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	if err := cw.w.Error(); err != nil {
+		return err
+	}
+	flush(cw.out)
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
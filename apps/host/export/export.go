@@ -0,0 +1,53 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+const (
+	// 每次查询的数量, 内存中最多只保留一页数据
+	pageSize = 500
+)
+
+// 按游标分页查询所有符合条件的主机, 每查询一页就写入并Flush一次, 返回导出的数量
+// 导出固定按(create_at, id)倒序, 不支持自定义排序
+func Export(ctx context.Context, svc host.Service, req *host.QueryHostRequest,
+	out io.Writer, format Format, cols []*Column) (int, error) {
+	if len(req.Sort) > 0 {
+		return 0, fmt.Errorf("export only supports the default order, sort is not allowed")
+	}
+	w, err := NewWriter(format, out, cols)
+	if err != nil {
+		return 0, err
+	}
+	req.UseCursor = true
+	req.Cursor = nil
+	req.PageSize = pageSize
+	req.WithTotal = false
+
+	total := 0
+	for {
+		set, err := svc.QueryHost(ctx, req)
+		if err != nil {
+			return total, err
+		}
+		for _, ins := range set.Items {
+			if err := w.WriteHost(ins); err != nil {
+				return total, err
+			}
+			total++
+		}
+		if err := w.Flush(); err != nil {
+			return total, err
+		}
+
+		if set.NextCursor == "" {
+			return total, w.Close()
+		}
+		req.Cursor = host.NewCursor(set.Items[len(set.Items)-1])
+	}
+}
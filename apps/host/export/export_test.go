@@ -0,0 +1,60 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/export"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	for i := 0; i < 3; i++ {
+		ins := host.NewDefaultHost()
+		ins.Namespace = host.DefaultNamespace
+		ins.Region = "hangzhou"
+		ins.Type = "sm1"
+		ins.Name = fmt.Sprintf("host0%d", i)
+		ins.CPU = i + 1
+		ins.Memory = 2048
+		_, err := memory.Service.CreateHost(ctx, ins)
+		should.NoError(err)
+	}
+
+	_, err := export.ParseColumns("name,password")
+	should.Error(err)
+	cols, err := export.ParseColumns("name, cpu")
+	if !should.NoError(err) {
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	total, err := export.Export(ctx, memory.Service, host.NewQueryHostRequest(), buf, export.CSV, cols)
+	if should.NoError(err) && should.Equal(3, total) {
+		records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(buf.Bytes(), []byte("\xEF\xBB\xBF")))).ReadAll()
+		should.NoError(err)
+		should.Equal([]string{"name", "cpu"}, records[0])
+		should.Len(records, 4)
+	}
+
+	buf.Reset()
+	_, err = export.Export(ctx, memory.Service, host.NewQueryHostRequest(), buf, export.XLSX, cols)
+	if should.NoError(err) {
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if should.NoError(err) {
+			should.Len(zr.File, 5)
+			should.Equal("xl/worksheets/sheet1.xml", zr.File[4].Name)
+		}
+	}
+}
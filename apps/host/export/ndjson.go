@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 每行一个JSON对象, 字段值和API返回的一致
+func newNDJSONWriter(w io.Writer, cols []*Column) *ndjsonWriter {
+	bw := bufio.NewWriter(w)
+	return &ndjsonWriter{
+		out:  w,
+		w:    bw,
+		enc:  json.NewEncoder(bw),
+		cols: cols,
+	}
+}
+
+type ndjsonWriter struct {
+	out  io.Writer
+	w    *bufio.Writer
+	enc  *json.Encoder
+	cols []*Column
+}
+
+func (nw *ndjsonWriter) WriteHost(h *host.Host) error {
+	obj := make(map[string]interface{}, len(nw.cols))
+	for _, c := range nw.cols {
+		obj[c.Name] = c.Value(h)
+	}
+	return nw.enc.Encode(obj)
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	if err := nw.w.Flush(); err != nil {
+		return err
+	}
+	flush(nw.out)
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.Flush()
+}
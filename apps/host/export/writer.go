@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+type Format string
+
+const (
+	CSV    = Format("csv")
+	NDJSON = Format("ndjson")
+	XLSX   = Format("xlsx")
+)
+
+// 导出文件的Content-Type
+func (f Format) ContentType() string {
+	switch f {
+	case NDJSON:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// 解析导出格式, 为空时默认csv
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return CSV, nil
+	case CSV, NDJSON, XLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("export format %s not supported, supported: csv, ndjson, xlsx", s)
+	}
+}
+
+// 文件扩展名
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// 按行写入主机数据, Flush 把已经写入的数据发送出去
+type Writer interface {
+	WriteHost(*host.Host) error
+	Flush() error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer, cols []*Column) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, cols)
+	case NDJSON:
+		return newNDJSONWriter(w, cols), nil
+	case XLSX:
+		return newXLSXWriter(w, cols)
+	default:
+		return nil, fmt.Errorf("export format %s not supported, supported: csv, ndjson, xlsx", format)
+	}
+}
+
+// 如果输出支持Flush(比如http.ResponseWriter), 立即把数据发送出去
+func flush(w io.Writer) {
+	if f, ok := w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,54 @@
+package host
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// 计算主机的ResourceHash和DescribeHash
+// 只包含描述主机本身的字段, Id, 各种时间戳和删除信息等不参与计算, 保证相同内容得到相同的Hash
+func (h *Host) ComputeHash() error {
+	res := *h.Resource
+	res.Id = ""
+	res.CreateAt = 0
+	res.UpdateAt = 0
+	res.SyncAt = 0
+	res.DeletedAt = 0
+	res.DeletedBy = ""
+	if len(res.Tags) == 0 {
+		res.Tags = nil
+	}
+
+	resHash, err := hash(res)
+	if err != nil {
+		return err
+	}
+	descHash, err := hash(h.Describe)
+	if err != nil {
+		return err
+	}
+
+	h.ResourceHash = resHash
+	h.DescribeHash = descHash
+	return nil
+}
+
+// 重新计算Hash, 并返回Resource和Describe是否发生了变化
+func (h *Host) Rehash() (resourceChanged, describeChanged bool, err error) {
+	oldRes, oldDesc := h.ResourceHash, h.DescribeHash
+	if err := h.ComputeHash(); err != nil {
+		return false, false, err
+	}
+	return h.ResourceHash != oldRes, h.DescribeHash != oldDesc, nil
+}
+
+// JSON序列化时map按key排序, 结构体按字段顺序, 结果是确定的
+func hash(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/export"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// 导出的写超时, 主机数量很多时60s的全局写超时不够, 超过后客户端会收到不完整的文件
+const exportWriteTimeout = 30 * time.Minute
+
+// 导出主机清单, 过滤参数和主机列表相同
+// format=csv|ndjson|xlsx 默认csv, columns=id,name,cpu 默认导出所有列
+func (h *handler) ExportHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req, err := host.NewQueryHostRequestFromHTTP(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if len(req.Sort) > 0 {
+		response.Failed(w, exception.NewBadRequest("export only supports the default order, sort is not allowed"))
+		return
+	}
+
+	// 数据开始写入后就无法再返回错误响应, 因此先校验所有参数
+	qs := r.URL.Query()
+	format, err := export.ParseFormat(qs.Get("format"))
+	if err != nil {
+		response.Failed(w, exception.NewBadRequest("%s", err))
+		return
+	}
+	cols, err := export.ParseColumns(qs.Get("columns"))
+	if err != nil {
+		response.Failed(w, exception.NewBadRequest("%s", err))
+		return
+	}
+
+	if err := router.SetWriteDeadline(r, time.Now().Add(exportWriteTimeout)); err != nil {
+		h.log.Warnf("extend export write deadline error, %s", err)
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="hosts-%s.%s"`,
+		time.Now().Format("20060102150405"), format.Extension()))
+
+	total, err := export.Export(r.Context(), h.host, req, w, format, cols)
+	if err != nil {
+		// 响应已经部分发送, 只能中断连接, 客户端会收到不完整的文件
+		h.log.Errorf("export host error, %d hosts exported, %s", total, err)
+		panic(http.ErrAbortHandler)
+	}
+	h.log.Debugf("export %d hosts as %s", total, format)
+}
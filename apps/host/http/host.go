@@ -2,10 +2,13 @@ package http
 
 import (
 	"net/http"
-	"strconv"
+	"time"
 
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
 
+	"github.com/infraboard/mcube/exception"
 	"github.com/infraboard/mcube/http/request"
 	"github.com/infraboard/mcube/http/response"
 	"github.com/julienschmidt/httprouter"
@@ -28,6 +31,17 @@ func (h *handler) CreateHost(w http.ResponseWriter, r *http.Request, _ httproute
 		response.Failed(w, err)
 		return
 	}
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if err := h.checkNamespace(r.Context(), allowed, req.Namespace); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	req.CreateBy = getOperator(r)
 
 	// 组装成Request对象, 调用Service方法
 	// 1. ctx: 一定要传递，如果用户中断里请求, 你的后段逻辑需不需中断
@@ -37,33 +51,59 @@ func (h *handler) CreateHost(w http.ResponseWriter, r *http.Request, _ httproute
 		response.Failed(w, err)
 		return
 	}
+	w.Header().Set("ETag", ins.ETag())
 	response.Success(w, ins)
 }
 
-// 查询主机列表, 分页查询
-func (h *handler) QueryHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	// query string
-	qs := r.URL.Query()
-
-	// 设置分页的默认值
-	var (
-		pageSize   = 20
-		pageNumber = 1
-	)
+// 批量创建Host, 返回每一条的录入结果
+func (h *handler) BatchCreateHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := host.NewBatchCreateHostRequest()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	// 每个命名空间只检查一次, 任意一个不合法时整批拒绝
+	operator := getOperator(r)
+	checked := map[string]bool{}
+	for _, item := range req.Items {
+		if item == nil {
+			continue
+		}
+		item.CreateBy = operator
+		if item.Resource == nil || checked[item.Namespace] {
+			continue
+		}
+		if err := h.checkNamespace(r.Context(), allowed, item.Namespace); err != nil {
+			response.Failed(w, err)
+			return
+		}
+		checked[item.Namespace] = true
+	}
 
-	// 从query string读取分页参数
-	pssStr := qs.Get("page_size")
-	if pssStr != "" {
-		pageSize, _ = strconv.Atoi(pssStr)
+	resp, err := h.host.BatchCreateHost(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
 	}
-	pnStr := qs.Get("page_number")
-	if pnStr != "" {
-		pageNumber, _ = strconv.Atoi(pnStr)
+	response.Success(w, resp)
+}
+
+// 查询主机列表, 分页查询
+func (h *handler) QueryHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// 从query string读取分页和过滤参数, 参数不合法时返回400
+	req, err := host.NewQueryHostRequestFromHTTP(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
 	}
-	req := &host.QueryHostRequest{
-		PageSize:   pageSize,
-		PageNumber: pageNumber,
-		Keywords:   qs.Get("keywords"),
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
 	}
 
 	set, err := h.host.QueryHost(r.Context(), req)
@@ -78,12 +118,76 @@ func (h *handler) QueryHost(w http.ResponseWriter, r *http.Request, _ httprouter
 	response.Success(w, set)
 }
 
+// 查询即将过期和已经过期的主机, within 默认为7d, 其他参数和主机列表相同
+func (h *handler) QueryExpiringHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req, err := host.NewQueryHostRequestFromHTTP(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	within := r.URL.Query().Get("within")
+	if within == "" {
+		within = "7d"
+	}
+	d, err := host.ParseWithin(within)
+	if err != nil {
+		response.Failed(w, exception.NewBadRequest("%s", err))
+		return
+	}
+	req.ExpireBefore = time.Now().Add(d).UnixNano() / 1000000
+
+	// 默认最先过期的排在前面
+	if len(req.Sort) == 0 && !req.UseCursor {
+		req.Sort = []*host.SortBy{{Field: "expire_at"}}
+	}
+
+	set, err := h.host.QueryHost(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
+
+// 按字段分组统计主机, 过滤参数和主机列表相同: group_by=vendor,region&status=running
+func (h *handler) StatsHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req, err := host.NewStatsHostRequestFromHTTP(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	set, err := h.host.StatsHost(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
+
 // 查询主机列表, 分页查询
 // httprouter params 保存这 路径参数
 func (h *handler) DescribeHost(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
 
 	req := &host.DesribeHostRequest{
 		Id: ps.ByName("id"),
+		// deleted=true 时可以查看回收站中的主机
+		WithDeleted:       r.URL.Query().Get("deleted") == "true",
+		AllowedNamespaces: allowed,
 	}
 
 	set, err := h.host.DesribeHost(r.Context(), req)
@@ -91,6 +195,7 @@ func (h *handler) DescribeHost(w http.ResponseWriter, r *http.Request, ps httpro
 		response.Failed(w, err)
 		return
 	}
+	w.Header().Set("ETag", set.ETag())
 
 	// 传递的是一个对象
 	// success, 会把你这个对象序列化成一个JSON
@@ -102,17 +207,35 @@ func (h *handler) UpdateHost(w http.ResponseWriter, r *http.Request, ps httprout
 
 	req := host.NewPutUpdateHostRequest()
 	// 解析HTTP协议, 通过Json反序列化, json --> Request
-	if err := request.GetDataFromRequest(r, req); err != nil {
+	err := request.GetDataFromRequest(r, req)
+	if err != nil {
 		response.Failed(w, err)
 		return
 	}
 
 	req.Id = ps.ByName("id")
+	req.UpdateBy = getOperator(r)
+	req.IfMatch, err = h.getIfMatch(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	// 修改命名空间相当于把主机移动过去
+	if err := h.checkNamespace(r.Context(), req.AllowedNamespaces, req.Namespace); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
 	set, err := h.host.UpdateHost(r.Context(), req)
 	if err != nil {
 		response.Failed(w, err)
 		return
 	}
+	w.Header().Set("ETag", set.ETag())
 
 	// 传递的是一个对象
 	// success, 会把你这个对象序列化成一个JSON
@@ -124,17 +247,35 @@ func (h *handler) PatchHost(w http.ResponseWriter, r *http.Request, ps httproute
 
 	req := host.NewPatchUpdateHostRequest()
 	// 解析HTTP协议, 通过Json反序列化, json --> Request
-	if err := request.GetDataFromRequest(r, req); err != nil {
+	err := request.GetDataFromRequest(r, req)
+	if err != nil {
 		response.Failed(w, err)
 		return
 	}
 
 	req.Id = ps.ByName("id")
+	req.UpdateBy = getOperator(r)
+	req.IfMatch, err = h.getIfMatch(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	// 修改命名空间相当于把主机移动过去
+	if err := h.checkNamespace(r.Context(), req.AllowedNamespaces, req.Namespace); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
 	set, err := h.host.UpdateHost(r.Context(), req)
 	if err != nil {
 		response.Failed(w, err)
 		return
 	}
+	w.Header().Set("ETag", set.ETag())
 
 	// 传递的是一个对象
 	// success, 会把你这个对象序列化成一个JSON
@@ -142,12 +283,61 @@ func (h *handler) PatchHost(w http.ResponseWriter, r *http.Request, ps httproute
 	response.Success(w, set)
 }
 
+// 按厂商和实例Id录入或者更新主机, 用于同步脚本等只知道实例Id的场景
+func (h *handler) UpsertHost(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req := host.NewUpsertHostRequest()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	// 以路径参数为准
+	vendor, err := host.ParseVendor(ps.ByName("vendor"))
+	if err != nil {
+		response.Failed(w, exception.NewBadRequest("vendor invalid, %s", err))
+		return
+	}
+	req.Vendor = vendor
+	req.InstanceId = ps.ByName("instance_id")
+	req.UpdateBy = getOperator(r)
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if err := h.checkNamespace(r.Context(), req.AllowedNamespaces, req.Namespace); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	resp, err := h.host.UpsertHost(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	w.Header().Set("ETag", resp.ETag())
+	response.Success(w, resp)
+}
+
 // 删除主机
 // httprouter params 保存这 路径参数
 func (h *handler) DeleteHost(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 
+	ifMatch, err := h.getIfMatch(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+
 	req := &host.DeleteHostRequest{
-		Id: ps.ByName("id"),
+		Id:                ps.ByName("id"),
+		DeleteBy:          getOperator(r),
+		IfMatch:           ifMatch,
+		AllowedNamespaces: allowed,
 	}
 
 	set, err := h.host.DeleteHost(r.Context(), req)
@@ -155,9 +345,55 @@ func (h *handler) DeleteHost(w http.ResponseWriter, r *http.Request, ps httprout
 		response.Failed(w, err)
 		return
 	}
+	w.Header().Set("ETag", set.ETag())
 
 	// 传递的是一个对象
 	// success, 会把你这个对象序列化成一个JSON
 	// 补充返回的数据
 	response.Success(w, set)
 }
+
+// 从回收站恢复主机
+func (h *handler) RestoreHost(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	req := &host.RestoreHostRequest{
+		Id:                ps.ByName("id"),
+		RestoreBy:         getOperator(r),
+		AllowedNamespaces: allowed,
+	}
+
+	ins, err := h.host.RestoreHost(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	w.Header().Set("ETag", ins.ETag())
+	response.Success(w, ins)
+}
+
+// 操作人, 认证通过时为当前用户, 关闭认证时由调用方通过 X-Operator Header 传递
+func getOperator(r *http.Request) string {
+	if u := user.FromContext(r.Context()); u != nil {
+		return u.Username
+	}
+	return r.Header.Get("X-Operator")
+}
+
+// 解析If-Match Header, 配置要求必须携带时缺失返回428
+func (h *handler) getIfMatch(r *http.Request) (int64, error) {
+	v := r.Header.Get("If-Match")
+	if v == "" && h.requireIfMatch {
+		return 0, exception.NewAPIException(exception.GlobalNamespace.String(), http.StatusPreconditionRequired, "", "If-Match header required")
+	}
+
+	version, err := host.ParseIfMatch(v)
+	if err != nil {
+		return 0, exception.NewBadRequest("%s", err)
+	}
+	return version, nil
+}
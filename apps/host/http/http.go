@@ -3,18 +3,26 @@ package http
 import (
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
 
 	"github.com/infraboard/mcube/logger"
 	"github.com/infraboard/mcube/logger/zap"
-	"github.com/julienschmidt/httprouter"
 )
 
 // Host 模块的 HTTP API 服务实例
 var API = handler{}
 
 type handler struct {
-	host host.Service
-	log  logger.Logger
+	host      host.Service
+	namespace namespace.Service
+	log       logger.Logger
+
+	// 修改和删除主机时是否必须携带If-Match Header
+	requireIfMatch bool
 }
 
 // 初始化的时候 依赖外部Host Service的实例对象  svr host.Service
@@ -25,15 +33,33 @@ func (h *handler) Init() {
 		panic("dependence host service is nil")
 	}
 	h.host = apps.Host
+
+	if apps.Namespace == nil {
+		panic("dependence namespace service is nil")
+	}
+	h.namespace = apps.Namespace
+	h.requireIfMatch = conf.C().App.RequireIfMatch
 }
 
 // 把Handler 实现的方法 注册给主路由
-func (h *handler) Registry(r *httprouter.Router) {
-	r.POST("/hosts", h.CreateHost)
-	r.GET("/hosts", h.QueryHost)
+func (h *handler) Registry(r *router.Router) {
+	r.POST("/hosts", permission.Required(policy.HostWrite, h.CreateHost))
+	r.POST("/hosts:batch", permission.Required(policy.HostWrite, h.BatchCreateHost))
+	r.POST("/hosts:import", permission.Required(policy.HostWrite, h.ImportHost))
+	r.GET("/hosts", permission.Required(policy.HostRead, h.QueryHost))
+	r.GET("/hosts/expiring", permission.Required(policy.HostRead, h.QueryExpiringHost))
+	r.GET("/hosts/stats", permission.Required(policy.HostRead, h.StatsHost))
+	r.GET("/hosts/export", permission.Required(policy.HostRead, h.ExportHost))
 	// 路径匹配，路径参数/hosts/110001
-	r.GET("/hosts/:id", h.DescribeHost)
-	r.PUT("/hosts/:id", h.UpdateHost)
-	r.PATCH("/hosts/:id", h.PatchHost)
-	r.DELETE("/hosts/:id", h.DeleteHost)
+	r.GET("/hosts/:id", permission.Required(policy.HostRead, h.DescribeHost))
+	r.PUT("/hosts/:id", permission.Required(policy.HostWrite, h.UpdateHost))
+	r.PATCH("/hosts/:id", permission.Required(policy.HostWrite, h.PatchHost))
+	// 删除和恢复只有管理员可以操作
+	r.DELETE("/hosts/:id", permission.Required(policy.HostDelete, h.DeleteHost))
+	r.PUT("/hosts/by-instance/:vendor/:instance_id", permission.Required(policy.HostWrite, h.UpsertHost))
+	r.POST("/hosts/:id/restore", permission.Required(policy.HostDelete, h.RestoreHost))
+	// 主机的历史版本
+	r.GET("/hosts/:id/revisions", permission.Required(policy.HostRead, h.QueryHostRevision))
+	r.GET("/hosts/:id/revisions/:rev", permission.Required(policy.HostRead, h.DescribeHostRevision))
+	r.GET("/hosts/:id/diff", permission.Required(policy.HostRead, h.DiffHostRevision))
 }
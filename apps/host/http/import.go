@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/importer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// 上传文件超过该大小时暂存到磁盘
+	maxImportMemory = 32 << 20
+)
+
+// 从CSV文件导入主机, multipart表单: file=<csv文件>
+// 参数: mode=create|upsert, dry_run=true, batch_size=100, map=<列名>=<字段名> 可以传多个
+// namespace=default 没有namespace列的行录入到该命名空间
+func (h *handler) ImportHost(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := r.ParseMultipartForm(maxImportMemory); err != nil {
+		response.Failed(w, exception.NewBadRequest("parse multipart form error, %s", err))
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		response.Failed(w, exception.NewBadRequest("csv file required, %s", err))
+		return
+	}
+	defer file.Close()
+
+	req := importer.NewImportRequest()
+	req.Operator = getOperator(r)
+	if v := r.FormValue("namespace"); v != "" {
+		req.Namespace = v
+	}
+	if req.AllowedNamespaces, err = permission.AllowedNamespaces(r); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	req.CheckNamespace = func(ctx context.Context, ns string) error {
+		return h.checkNamespace(ctx, req.AllowedNamespaces, ns)
+	}
+	if v := r.FormValue("mode"); v != "" {
+		req.Mode = importer.Mode(v)
+	}
+	if v := r.FormValue("dry_run"); v != "" {
+		if req.DryRun, err = strconv.ParseBool(v); err != nil {
+			response.Failed(w, exception.NewBadRequest("dry_run must be a bool, but got %s", v))
+			return
+		}
+	}
+	if req.BatchSize, err = parseInt(r.FormValue("batch_size"), "batch_size", req.BatchSize); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.Mapping, err = importer.ParseMapping(r.Form["map"]); err != nil {
+		response.Failed(w, exception.NewBadRequest("%s", err))
+		return
+	}
+
+	report, err := importer.Import(r.Context(), h.host, file, req)
+	if err != nil {
+		response.Failed(w, exception.NewBadRequest("import host error, %s", err))
+		return
+	}
+	response.Success(w, report)
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 录入主机或者把主机移动到其他命名空间时, 目标命名空间必须存在并且在访问范围内
+// 命名空间为空时由后续的参数校验报错
+func (h *handler) checkNamespace(ctx context.Context, allowed []string, ns string) error {
+	if ns == "" {
+		return nil
+	}
+	if !host.NamespaceAllowed(allowed, ns) {
+		return exception.NewPermissionDeny("no permission to namespace %s", ns)
+	}
+
+	_, err := h.namespace.DescribeNamespace(ctx, namespace.NewDescribeNamespaceRequest(ns))
+	if exception.IsNotFoundError(err) {
+		return exception.NewBadRequest("namespace %s not exists", ns)
+	}
+	return err
+}
+
+// 检查当前用户是否可以访问主机, 用于历史版本等不经过主机查询的接口
+// 访问范围不受限时不检查, 主机被彻底清理后仍然可以查看历史版本
+func (h *handler) checkHostAccess(r *http.Request, id string) error {
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil || allowed == nil {
+		return err
+	}
+
+	_, err = h.host.DesribeHost(r.Context(), &host.DesribeHostRequest{
+		Id:                id,
+		WithDeleted:       true,
+		AllowedNamespaces: allowed,
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// 查询主机的历史版本列表
+func (h *handler) QueryHostRevision(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := h.checkHostAccess(r, ps.ByName("id")); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	qs := r.URL.Query()
+	req := host.NewQueryHostRevisionRequest(ps.ByName("id"))
+
+	var err error
+	if req.PageSize, err = parseInt(qs.Get("page_size"), "page_size", req.PageSize); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.PageNumber, err = parseInt(qs.Get("page_number"), "page_number", req.PageNumber); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.Failed(w, exception.NewBadRequest("%s", err))
+		return
+	}
+
+	set, err := h.host.QueryHostRevision(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
+
+// 查询主机的某个历史版本
+func (h *handler) DescribeHostRevision(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	rev, err := parseInt(ps.ByName("rev"), "rev", 0)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if err := h.checkHostAccess(r, ps.ByName("id")); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.host.DescribeHostRevision(r.Context(), &host.DescribeHostRevisionRequest{
+		Id:       ps.ByName("id"),
+		Revision: rev,
+	})
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 对比两个版本: /hosts/:id/diff?from=1&to=3, 默认对比最新版本和上一个版本
+func (h *handler) DiffHostRevision(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := h.checkHostAccess(r, ps.ByName("id")); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	qs := r.URL.Query()
+	req := &host.DiffHostRevisionRequest{Id: ps.ByName("id")}
+
+	var err error
+	if req.From, err = parseInt(qs.Get("from"), "from", 0); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.To, err = parseInt(qs.Get("to"), "to", 0); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	diff, err := h.host.DiffHostRevision(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, diff)
+}
+
+// 解析非负整数参数, 为空时使用默认值
+func parseInt(v, name string, defaultValue int) (int, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, exception.NewBadRequest("%s must be a non-negative integer, but got %s", name, v)
+	}
+	return n, nil
+}
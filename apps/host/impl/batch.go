@@ -0,0 +1,196 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+	"github.com/rs/xid"
+)
+
+func (i *impl) BatchCreateHost(ctx context.Context, req *host.BatchCreateHostRequest) (*host.BatchCreateHostResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate batch create host request error, %s", err)
+	}
+
+	// 先逐条校验, 记录每条的结果
+	results := make([]*host.BatchCreateHostResult, len(req.Items))
+	valid := []int{}
+	for idx, ins := range req.Items {
+		results[idx] = &host.BatchCreateHostResult{Index: idx}
+		if ins == nil {
+			results[idx].Error = "host is null"
+			continue
+		}
+
+		ins.Id = xid.New().String()
+		ins.Version = 1
+		if ins.Resource != nil && ins.CreateAt == 0 {
+			ins.CreateAt = ftime.Now().Timestamp()
+		}
+		if err := ins.Validate(); err != nil {
+			results[idx].Error = err.Error()
+			continue
+		}
+		if err := ins.ComputeHash(); err != nil {
+			results[idx].Error = err.Error()
+			continue
+		}
+		ins.DescribeChangedAt = ins.CreateAt
+		valid = append(valid, idx)
+	}
+
+	switch {
+	case req.AllOrNothing && len(valid) < len(req.Items):
+		// 有数据校验失败, 全部不录入
+		for _, idx := range valid {
+			results[idx].Error = "aborted, other items in the batch are invalid"
+		}
+	case req.AllOrNothing:
+		// 所有数据在一个事务里录入
+		if err := i.batchInsert(ctx, req.Items); err != nil {
+			for _, idx := range valid {
+				results[idx].Error = err.Error()
+			}
+		} else {
+			for _, idx := range valid {
+				results[idx].Success = true
+			}
+		}
+	default:
+		// 按块录入, 每块一个事务, 某一块失败不影响其他块
+		for start := 0; start < len(valid); start += batchChunkSize {
+			end := start + batchChunkSize
+			if end > len(valid) {
+				end = len(valid)
+			}
+
+			chunk := make([]*host.Host, 0, end-start)
+			for _, idx := range valid[start:end] {
+				chunk = append(chunk, req.Items[idx])
+			}
+
+			if err := i.batchInsert(ctx, chunk); err == nil {
+				for _, idx := range valid[start:end] {
+					results[idx].Success = true
+				}
+				continue
+			}
+
+			// 整块失败时逐条重试, 每一条记录自己的结果
+			for _, idx := range valid[start:end] {
+				if err := i.batchInsert(ctx, []*host.Host{req.Items[idx]}); err != nil {
+					results[idx].Error = err.Error()
+				} else {
+					results[idx].Success = true
+				}
+			}
+		}
+	}
+
+	resp := host.NewBatchCreateHostResponse()
+	for _, r := range results {
+		if r.Success {
+			r.Id = req.Items[r.Index].Id
+		}
+		resp.Add(r)
+	}
+	return resp, nil
+}
+
+// 在一个事务里使用多行INSERT语句录入主机
+func (i *impl) batchInsert(ctx context.Context, hosts []*host.Host) (err error) {
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// 函数执行完成后, 专门判断事务是否正常
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			i.log.Debugf("tx rollback error, %s", err)
+		} else {
+			err := tx.Commit()
+			i.log.Debugf("tx commit error, %s", err)
+		}
+	}()
+
+	for start := 0; start < len(hosts); start += batchChunkSize {
+		end := start + batchChunkSize
+		if end > len(hosts) {
+			end = len(hosts)
+		}
+		chunk := hosts[start:end]
+
+		var (
+			resArgs  []interface{}
+			descArgs []interface{}
+			tagArgs  []interface{}
+			tagRows  int
+			revArgs  []interface{}
+		)
+		for _, ins := range chunk {
+			sealed, err := sealHost(i.cipher, ins)
+			if err != nil {
+				return err
+			}
+			resArgs = append(resArgs,
+				ins.Id, ins.Vendor, ins.Region, ins.Zone, ins.CreateAt, ins.ExpireAt, ins.Category, ins.Type, ins.InstanceId,
+				ins.Name, ins.Description, ins.Status, ins.UpdateAt, ins.SyncAt, sealed.SyncAccount, ins.PublicIP, ins.PrivateIP, ins.PayType, ins.ResourceHash, ins.DescribeHash,
+				ins.DescribeChangedAt, ins.Namespace,
+			)
+			descArgs = append(descArgs,
+				ins.Id, ins.CPU, ins.Memory, ins.GPUAmount, ins.GPUSpec, ins.OSType, ins.OSName,
+				ins.SerialNumber, ins.ImageID, ins.InternetMaxBandwidthOut,
+				ins.InternetMaxBandwidthIn, sealed.KeyPairName, ins.SecurityGroups,
+			)
+			for k, v := range ins.Tags {
+				tagArgs = append(tagArgs, ins.Id, k, v)
+				tagRows++
+			}
+
+			// 新录入的主机, 版本号从1开始
+			rev := host.NewRevision(ins, host.ActionCreate, ins.CreateBy)
+			data, err := i.marshalRevision(rev.Host)
+			if err != nil {
+				return err
+			}
+			revArgs = append(revArgs, rev.HostId, 1, rev.Action, rev.Operator, rev.CreateAt, data)
+		}
+
+		if _, err = tx.ExecContext(ctx, batchInsertResourceSQL+valuesStmt(len(chunk), 22), resArgs...); err != nil {
+			// 厂商和实例Id的唯一索引冲突, 多行时无法确定是哪一条
+			if isDuplicateEntry(err) && len(chunk) == 1 {
+				return duplicateError(err, chunk[0])
+			}
+			if isDuplicateEntry(err) {
+				return exception.NewConflict("batch insert resource conflict, %s", err)
+			}
+			return fmt.Errorf("batch insert resource error, %s", err)
+		}
+		if _, err = tx.ExecContext(ctx, batchInsertDescribeSQL+valuesStmt(len(chunk), 13), descArgs...); err != nil {
+			return fmt.Errorf("batch insert host error, %s", err)
+		}
+		if tagRows > 0 {
+			if _, err = tx.ExecContext(ctx, batchInsertTagSQL+valuesStmt(tagRows, 3), tagArgs...); err != nil {
+				return fmt.Errorf("batch insert tag error, %s", err)
+			}
+		}
+		if _, err = tx.ExecContext(ctx, batchInsertRevisionSQL+valuesStmt(len(chunk), 6), revArgs...); err != nil {
+			return fmt.Errorf("batch insert revision error, %s", err)
+		}
+	}
+
+	return nil
+}
+
+// 生成多行INSERT的VALUES部分: (?,?),(?,?)
+func valuesStmt(rows, columns int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?,", columns), ",") + ")"
+	return strings.TrimSuffix(strings.Repeat(row+",", rows), ",")
+}
@@ -0,0 +1,4 @@
+package impl
+
+// 只在测试中导出, 用于校验生成的SQL
+var BuildQueryOrder = buildQueryOrder
@@ -5,7 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 
-	"gitee.com/go-course/restful-api-demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
 	"github.com/infraboard/mcube/exception"
 	"github.com/infraboard/mcube/sqlbuilder"
 	"github.com/infraboard/mcube/types/ftime"
@@ -13,19 +14,34 @@ import (
 )
 
 func (i *impl) CreateHost(ctx context.Context, ins *host.Host) (*host.Host, error) {
-
-	// 校验数据合法性
-	if err := ins.Validate(); err != nil {
-		return nil, err
+	if ins.Resource == nil || ins.Describe == nil {
+		return nil, exception.NewBadRequest("host resource and describe required")
 	}
 
 	// 生成UUID的一个库,
 	// snow 雪花算法
 	// 分布式id, app, instance, ip, mac, ......, idc(), region,
+	// Id由服务端生成, 和批量录入一样先生成再校验
 	ins.Id = xid.New().String()
+
+	// 校验数据合法性
+	if err := ins.Validate(); err != nil {
+		return nil, err
+	}
+	ins.Version = 1
 	if ins.CreateAt == 0 {
 		ins.CreateAt = ftime.Now().Timestamp()
 	}
+	if err := ins.ComputeHash(); err != nil {
+		return nil, err
+	}
+	ins.DescribeChangedAt = ins.CreateAt
+
+	// 敏感字段加密后入库
+	sealed, err := sealHost(i.cipher, ins)
+	if err != nil {
+		return nil, err
+	}
 
 	// 把数据入库到resource表和host表
 	// 一次需要往2个表录入数据, 我们需要2个操作，要么都成功，要么都失败, 事物的逻辑
@@ -34,7 +50,6 @@ func (i *impl) CreateHost(ctx context.Context, ins *host.Host) (*host.Host, erro
 	var (
 		resStmt  *sql.Stmt
 		descStmt *sql.Stmt
-		err      error
 	)
 
 	// 初始化一个事务, 所有的操作都使用这个事务来进行提交
@@ -68,9 +83,11 @@ func (i *impl) CreateHost(ctx context.Context, ins *host.Host) (*host.Host, erro
 	// 注意: Prepare 语句会占用mysql资源, 如果使用不关闭, 会导致 Prepare溢出, 是全局的
 	_, err = resStmt.Exec(
 		ins.Id, ins.Vendor, ins.Region, ins.Zone, ins.CreateAt, ins.ExpireAt, ins.Category, ins.Type, ins.InstanceId,
-		ins.Name, ins.Description, ins.Status, ins.UpdateAt, ins.SyncAt, ins.SyncAccount, ins.PublicIP, ins.PrivateIP, ins.PayType, ins.ResourceHash, ins.DescribeHash,
+		ins.Name, ins.Description, ins.Status, ins.UpdateAt, ins.SyncAt, sealed.SyncAccount, ins.PublicIP, ins.PrivateIP, ins.PayType, ins.ResourceHash, ins.DescribeHash,
+		ins.DescribeChangedAt, ins.Namespace,
 	)
 	if err != nil {
+		err = duplicateError(err, ins)
 		return nil, err
 	}
 
@@ -84,22 +101,50 @@ func (i *impl) CreateHost(ctx context.Context, ins *host.Host) (*host.Host, erro
 	_, err = descStmt.Exec(
 		ins.Id, ins.CPU, ins.Memory, ins.GPUAmount, ins.GPUSpec, ins.OSType, ins.OSName,
 		ins.SerialNumber, ins.ImageID, ins.InternetMaxBandwidthOut,
-		ins.InternetMaxBandwidthIn, ins.KeyPairName, ins.SecurityGroups,
+		ins.InternetMaxBandwidthIn, sealed.KeyPairName, ins.SecurityGroups,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// 标签存入resource_tag表
+	err = i.saveTags(ctx, tx, ins)
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录第一个版本
+	err = i.saveRevision(ctx, tx, host.NewRevision(ins, host.ActionCreate, ins.CreateBy))
+	if err != nil {
+		return nil, err
+	}
+
 	return ins, nil
 }
 
 func (i *impl) QueryHost(ctx context.Context, req *host.QueryHostRequest) (*host.Set, error) {
-	query := sqlbuilder.NewQuery(queryHostSQL).Order("create_at").Desc().Limit(int64(req.Offset()), uint(req.PageSize))
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query host request error, %s", err)
+	}
+
+	query := sqlbuilder.NewQuery(queryHostSQL)
+
+	// 关键字, 标签和结构化过滤条件
+	i.buildQueryFilter(query, req)
+	// 排序
+	buildQueryOrder(query, req)
 
-	// 用户输入了关键字
-	// Prepare 占位符是?  '%kws%' 是一个整体, 是一个值
-	if req.Keywords != "" {
-		query.Where("r.name LIKE ?", "%"+req.Keywords+"%")
+	// Count 语句只需要过滤条件, 不包含游标条件
+	countStr, countArgs := query.BuildCount()
+
+	if req.UseCursor {
+		// 游标分页: 多查询一条, 用于判断是否还有下一页
+		if req.Cursor != nil {
+			query.Where("(r.create_at < ? OR (r.create_at = ? AND r.id < ?))", req.Cursor.CreateAt, req.Cursor.CreateAt, req.Cursor.Id)
+		}
+		query.Limit(0, uint(req.PageSize+1))
+	} else {
+		query.Limit(int64(req.Offset()), uint(req.PageSize))
 	}
 
 	sqlStr, args := query.BuildQuery()
@@ -116,32 +161,38 @@ func (i *impl) QueryHost(ctx context.Context, req *host.QueryHostRequest) (*host
 	if err != nil {
 		return nil, fmt.Errorf("stmt query error, %s", err)
 	}
+	defer rows.Close()
 
 	// 初始化需要返回的对象
 	set := host.NewSet()
 
 	// 迭代查询表里的数据
 	for rows.Next() {
-		ins := host.NewDefaultHost()
-		if err := rows.Scan(
-			&ins.Id, &ins.Vendor, &ins.Region, &ins.Zone, &ins.CreateAt, &ins.ExpireAt,
-			&ins.Category, &ins.Type, &ins.InstanceId, &ins.Name,
-			&ins.Description, &ins.Status, &ins.UpdateAt, &ins.SyncAt, &ins.SyncAccount,
-			&ins.PublicIP, &ins.PrivateIP, &ins.PayType, &ins.ResourceHash, &ins.DescribeHash,
-			&ins.Id, &ins.CPU,
-			&ins.Memory, &ins.GPUAmount, &ins.GPUSpec, &ins.OSType, &ins.OSName,
-			&ins.SerialNumber, &ins.ImageID, &ins.InternetMaxBandwidthOut, &ins.InternetMaxBandwidthIn,
-			&ins.KeyPairName, &ins.SecurityGroups,
-		); err != nil {
+		ins, err := i.scanHost(rows)
+		if err != nil {
 			return nil, err
 		}
 		set.Add(ins)
 
 	}
 
+	// 多查询出的一条说明还有下一页, 以当前页最后一条生成游标
+	if req.UseCursor && len(set.Items) > req.PageSize {
+		set.Items = set.Items[:req.PageSize]
+		set.NextCursor = host.NewCursor(set.Items[req.PageSize-1]).Encode()
+	}
+
+	// 补充主机的标签
+	if err := i.loadTags(ctx, set.Items...); err != nil {
+		return nil, err
+	}
+
+	// 大数据量时COUNT查询比较慢, 由调用方决定是否统计
+	if !req.WithTotal {
+		return set, nil
+	}
+
 	// Count 获取总数据量
-	// build 一个count语句
-	countStr, countArgs := query.BuildCount()
 	countStmt, err := i.db.Prepare(countStr)
 	if err != nil {
 		return nil, fmt.Errorf("prepare count stmt error, %s", err)
@@ -156,7 +207,17 @@ func (i *impl) QueryHost(ctx context.Context, req *host.QueryHostRequest) (*host
 }
 
 func (i *impl) DesribeHost(ctx context.Context, req *host.DesribeHostRequest) (*host.Host, error) {
-	query := sqlbuilder.NewQuery(queryHostSQL).Where("r.id = ?", req.Id)
+	query := sqlbuilder.NewQuery(queryHostSQL)
+	if req.Id != "" {
+		query.Where("r.id = ?", req.Id)
+	} else {
+		query.Where("r.vendor = ? AND r.instance_id = ?", req.Vendor, req.InstanceId)
+	}
+	if !req.WithDeleted {
+		query.Where("r.deleted_at = 0")
+	}
+	// 其他命名空间的主机按不存在处理
+	whereAllowed(query, req.AllowedNamespaces)
 
 	sqlStr, args := query.BuildQuery()
 	i.log.Debugf("sql: %s, args: %v", sqlStr, args)
@@ -168,23 +229,17 @@ func (i *impl) DesribeHost(ctx context.Context, req *host.DesribeHostRequest) (*
 	}
 	defer stmt.Close()
 
-	ins := host.NewDefaultHost()
-	err = stmt.QueryRow(args...).Scan(
-		&ins.Id, &ins.Vendor, &ins.Region, &ins.Zone, &ins.CreateAt, &ins.ExpireAt,
-		&ins.Category, &ins.Type, &ins.InstanceId, &ins.Name,
-		&ins.Description, &ins.Status, &ins.UpdateAt, &ins.SyncAt, &ins.SyncAccount,
-		&ins.PublicIP, &ins.PrivateIP, &ins.PayType, &ins.ResourceHash, &ins.DescribeHash,
-		&ins.Id, &ins.CPU,
-		&ins.Memory, &ins.GPUAmount, &ins.GPUSpec, &ins.OSType, &ins.OSName,
-		&ins.SerialNumber, &ins.ImageID, &ins.InternetMaxBandwidthOut, &ins.InternetMaxBandwidthIn,
-		&ins.KeyPairName, &ins.SecurityGroups,
-	)
+	ins, err := i.scanHost(stmt.QueryRow(args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, exception.NewNotFound("host %s not found", req.Id)
+			return nil, exception.NewNotFound("host %s not found", req.Key())
 		}
 		return nil, fmt.Errorf("stmt query error, %s", err)
 	}
+
+	if err := i.loadTags(ctx, ins); err != nil {
+		return nil, err
+	}
 	return ins, nil
 }
 
@@ -192,11 +247,17 @@ func (i *impl) DesribeHost(ctx context.Context, req *host.DesribeHostRequest) (*
 func (i *impl) UpdateHost(ctx context.Context, req *host.UpdateHostRequest) (*host.Host, error) {
 
 	// 重新查询出来
-	ins, err := i.DesribeHost(ctx, host.NewDescribeHostRequestWithID(req.Id))
+	ins, err := i.DesribeHost(ctx, &host.DesribeHostRequest{Id: req.Id, AllowedNamespaces: req.AllowedNamespaces})
 	if err != nil {
 		return nil, err
 	}
 
+	// 乐观锁: 客户端通过If-Match指定了期望的版本号
+	if err := host.CheckVersion(ins, req.IfMatch); err != nil {
+		return nil, err
+	}
+	version, updateAt, syncAt := ins.Version, ins.UpdateAt, ins.SyncAt
+
 	// 对象更新(PATCH/PUT)
 	switch req.UpdateMode {
 	case host.PUT:
@@ -214,48 +275,165 @@ func (i *impl) UpdateHost(ctx context.Context, req *host.UpdateHostRequest) (*ho
 	if err := ins.Validate(); err != nil {
 		return nil, err
 	}
+	// 不能把主机移动到没有权限的命名空间
+	if !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return nil, exception.NewPermissionDeny("no permission to namespace %s", ins.Namespace)
+	}
 
-	stmt, err := i.db.Prepare(updateResourceSQL)
+	// 内容没有变化时不写入, 避免产生无意义的版本
+	resChanged, descChanged, err := ins.Rehash()
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	if !resChanged && !descChanged {
+		ins.UpdateAt = updateAt
+		ins.Unchanged = true
+		// 同步时间记录最近一次看到实例的时间, 内容没有变化也需要刷新, 不产生新版本
+		if ins.SyncAt <= syncAt {
+			ins.SyncAt = syncAt
+			return ins, nil
+		}
+		if _, err := i.db.ExecContext(ctx, updateSyncAtSQL, ins.SyncAt, ins.Id); err != nil {
+			return nil, fmt.Errorf("update host sync_at error, %s", err)
+		}
+		return ins, nil
+	}
+	if descChanged {
+		ins.DescribeChangedAt = ins.UpdateAt
+	}
 
-	// DML
-	// vendor=?,region=?,zone=?,expire_at=?,name=?,descruotion=? WHERE id = ?
-	_, err = stmt.Exec(ins.Vendor, ins.Region, ins.Zone, ins.ExpireAt, ins.Name, ins.Description, ins.Id)
+	sealed, err := sealHost(i.cipher, ins)
 	if err != nil {
 		return nil, err
 	}
 
-	return ins, nil
-}
+	// 一次需要更新resource, host, resource_tag, host_revision 4个表, 使用事务
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
 
-// 自己模仿 Insert 使用事务一次完成2个SQL操作
-func (i *impl) DeleteHost(ctx context.Context, req *host.DeleteHostRequest) (*host.Host, error) {
+	// 函数执行完成后, 专门判断事务是否正常
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			i.log.Debugf("tx rollback error, %s", err)
+		} else {
+			err := tx.Commit()
+			i.log.Debugf("tx commit error, %s", err)
+		}
+	}()
 
-	// 全局异常
-	var (
-		resStmt  *sql.Stmt
-		descStmt *sql.Stmt
-		err      error
+	// DML, 版本号不匹配说明在读取之后被其他请求修改过
+	result, err := tx.ExecContext(ctx, updateResourceSQL,
+		ins.Namespace, ins.Vendor, ins.Region, ins.Zone, ins.ExpireAt, ins.Category, ins.Type, ins.InstanceId,
+		ins.Name, ins.Description, ins.Status, ins.UpdateAt, ins.SyncAt, sealed.SyncAccount,
+		ins.PublicIP, ins.PrivateIP, ins.PayType, ins.ResourceHash, ins.DescribeHash,
+		ins.DescribeChangedAt, ins.Id, version,
 	)
+	if err != nil {
+		err = duplicateError(err, ins)
+		return nil, err
+	}
+	var n int64
+	n, err = result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		err = host.NewPreconditionFailed("host %s has been modified by others, please retry", ins.Id)
+		return nil, err
+	}
+	ins.Version = version + 1
 
+	_, err = tx.ExecContext(ctx, updateHostSQL,
+		ins.CPU, ins.Memory, ins.GPUAmount, ins.GPUSpec, ins.OSType, ins.OSName,
+		ins.SerialNumber, ins.ImageID, ins.InternetMaxBandwidthOut,
+		ins.InternetMaxBandwidthIn, sealed.KeyPairName, ins.SecurityGroups, ins.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// PUT 替换标签, PATCH 合并标签, 最终的标签已经在对象更新时计算好
+	err = i.saveTags(ctx, tx, ins)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保存更新后的版本, 用于追溯变更历史
+	err = i.saveRevision(ctx, tx, host.NewRevision(ins, req.UpdateMode.Action(), req.UpdateBy))
+	if err != nil {
+		return nil, err
+	}
+
+	return ins, nil
+}
+
+// 软删除, 只标记删除时间和删除人, 数据保留在回收站中
+func (i *impl) DeleteHost(ctx context.Context, req *host.DeleteHostRequest) (ins *host.Host, err error) {
 	// 重新查询出来
-	ins, err := i.DesribeHost(ctx, host.NewDescribeHostRequestWithID(req.Id))
+	ins, err = i.DesribeHost(ctx, &host.DesribeHostRequest{Id: req.Id, AllowedNamespaces: req.AllowedNamespaces})
 	if err != nil {
 		return nil, err
 	}
 
-	// 初始化一个事务, 所有的操作都使用这个事务来进行提交
+	// 乐观锁: 客户端通过If-Match指定了期望的版本号
+	if err = host.CheckVersion(ins, req.IfMatch); err != nil {
+		return nil, err
+	}
+
 	tx, err := i.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			i.log.Debugf("tx rollback error, %s", err)
+		} else {
+			err := tx.Commit()
+			i.log.Debugf("tx commit error, %s", err)
+		}
+	}()
 
-	// 函数执行完成后, 专门判断事务是否正常
+	ins.DeletedAt = ftime.Now().Timestamp()
+	ins.DeletedBy = req.DeleteBy
+	result, err := tx.ExecContext(ctx, deleteResourceSQL, ins.DeletedAt, ins.DeletedBy, req.Id, ins.Version)
+	if err != nil {
+		return nil, err
+	}
+	// 并发修改或者删除时, 只有一个请求能成功
+	if n, _ := result.RowsAffected(); n == 0 {
+		err = host.NewPreconditionFailed("host %s has been modified by others, please retry", req.Id)
+		return nil, err
+	}
+	ins.Version++
+
+	// 记录删除人, 用于追溯
+	err = i.saveRevision(ctx, tx, host.NewRevision(ins, host.ActionDelete, req.DeleteBy))
+	if err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+// 从回收站中恢复主机
+func (i *impl) RestoreHost(ctx context.Context, req *host.RestoreHostRequest) (ins *host.Host, err error) {
+	ins, err = i.DesribeHost(ctx, &host.DesribeHostRequest{Id: req.Id, WithDeleted: true, AllowedNamespaces: req.AllowedNamespaces})
+	if err != nil {
+		return nil, err
+	}
+	if ins.DeletedAt == 0 {
+		err = exception.NewBadRequest("host %s is not deleted", req.Id)
+		return nil, err
+	}
+
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
-		// 事务执行有异常
 		if err != nil {
 			err := tx.Rollback()
 			i.log.Debugf("tx rollback error, %s", err)
@@ -265,25 +443,89 @@ func (i *impl) DeleteHost(ctx context.Context, req *host.DeleteHostRequest) (*ho
 		}
 	}()
 
-	resStmt, err = tx.Prepare(deleteResourceSQL)
+	result, err := tx.ExecContext(ctx, restoreResourceSQL, req.Id)
 	if err != nil {
 		return nil, err
 	}
-	defer resStmt.Close()
-	_, err = resStmt.Exec(req.Id)
+	if n, _ := result.RowsAffected(); n == 0 {
+		err = exception.NewNotFound("deleted host %s not found", req.Id)
+		return nil, err
+	}
+
+	ins.DeletedAt = 0
+	ins.DeletedBy = ""
+	ins.Version++
+
+	err = i.saveRevision(ctx, tx, host.NewRevision(ins, host.ActionRestore, req.RestoreBy))
 	if err != nil {
 		return nil, err
 	}
+	return ins, nil
+}
 
-	descStmt, err = tx.Prepare(deleteHostSQL)
+// 彻底清除回收站中超过保留期的主机, 连同host和resource_tag表中的数据
+func (i *impl) PurgeHost(ctx context.Context, req *host.PurgeHostRequest) (resp *host.PurgeHostResponse, err error) {
+	tx, err := i.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer descStmt.Close()
-	_, err = descStmt.Exec(req.Id)
+
+	// 函数执行完成后, 专门判断事务是否正常
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			i.log.Debugf("tx rollback error, %s", err)
+		} else {
+			err := tx.Commit()
+			i.log.Debugf("tx commit error, %s", err)
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, purgeRevisionSQL, req.DeletedBefore); err != nil {
+		return nil, fmt.Errorf("purge host revision error, %s", err)
+	}
+	if _, err = tx.ExecContext(ctx, purgeTagSQL, req.DeletedBefore); err != nil {
+		return nil, fmt.Errorf("purge host tag error, %s", err)
+	}
+	if _, err = tx.ExecContext(ctx, purgeHostSQL, req.DeletedBefore); err != nil {
+		return nil, fmt.Errorf("purge host describe error, %s", err)
+	}
+	result, err := tx.ExecContext(ctx, purgeResourceSQL, req.DeletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("purge host resource error, %s", err)
+	}
+
+	resp = &host.PurgeHostResponse{}
+	resp.Count, err = result.RowsAffected()
 	if err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+// 兼容 *sql.Row 和 *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// 按 queryHostSQL 的字段顺序读取主机数据, 并解密敏感字段
+func (i *impl) scanHost(row scanner) (*host.Host, error) {
+	ins := host.NewDefaultHost()
+	err := row.Scan(
+		&ins.Id, &ins.Namespace, &ins.Vendor, &ins.Region, &ins.Zone, &ins.CreateAt, &ins.ExpireAt,
+		&ins.Category, &ins.Type, &ins.InstanceId, &ins.Name,
+		&ins.Description, &ins.Status, &ins.UpdateAt, &ins.SyncAt, &ins.SyncAccount,
+		&ins.PublicIP, &ins.PrivateIP, &ins.PayType, &ins.ResourceHash, &ins.DescribeHash,
+		&ins.DescribeChangedAt, &ins.DeletedAt, &ins.DeletedBy, &ins.Version, &ins.CPU,
+		&ins.Memory, &ins.GPUAmount, &ins.GPUSpec, &ins.OSType, &ins.OSName,
+		&ins.SerialNumber, &ins.ImageID, &ins.InternetMaxBandwidthOut, &ins.InternetMaxBandwidthIn,
+		&ins.KeyPairName, &ins.SecurityGroups,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := openHost(i.cipher, ins); err != nil {
+		return nil, err
+	}
 	return ins, nil
 }
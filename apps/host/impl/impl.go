@@ -4,6 +4,7 @@ import (
 	"database/sql"
 
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/secret"
 
 	"github.com/infraboard/mcube/logger"
 	"github.com/infraboard/mcube/logger/zap"
@@ -18,6 +19,10 @@ type impl struct {
 
 	// 依赖数据库
 	db *sql.DB
+	// 关键字搜索方式
+	search searcher
+	// 敏感字段加密, 密钥来自App.Key
+	cipher *secret.Cipher
 }
 
 func (i *impl) Init() error {
@@ -29,5 +34,15 @@ func (i *impl) Init() error {
 	}
 
 	i.db = db
+
+	i.search, err = newSearcher(conf.C().App.Search)
+	if err != nil {
+		return err
+	}
+
+	i.cipher, err = secret.NewCipher(conf.C().App.Key)
+	if err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,168 @@
+package impl
+
+import (
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+var (
+	// 排序字段对应的表字段
+	sortColumns = map[string]string{
+		"namespace":                  "r.namespace",
+		"vendor":                     "r.vendor",
+		"region":                     "r.region",
+		"zone":                       "r.zone",
+		"create_at":                  "r.create_at",
+		"expire_at":                  "r.expire_at",
+		"update_at":                  "r.update_at",
+		"sync_at":                    "r.sync_at",
+		"category":                   "r.category",
+		"type":                       "r.type",
+		"instance_id":                "r.instance_id",
+		"name":                       "r.name",
+		"status":                     "r.status",
+		"public_ip":                  "r.public_ip",
+		"private_ip":                 "r.private_ip",
+		"pay_type":                   "r.pay_type",
+		"cpu":                        "h.cpu",
+		"memory":                     "h.memory",
+		"gpu_amount":                 "h.gpu_amount",
+		"os_type":                    "h.os_type",
+		"os_name":                    "h.os_name",
+		"internet_max_bandwidth_out": "h.internet_max_bandwidth_out",
+		"internet_max_bandwidth_in":  "h.internet_max_bandwidth_in",
+	}
+)
+
+// 把查询请求中的排序字段翻译成Order语句, 默认按(create_at, id)倒序, 和游标分页的顺序一致
+func buildQueryOrder(query *sqlbuilder.Builder, req *host.QueryHostRequest) {
+	if len(req.Sort) == 0 {
+		// 有关键字时精确匹配的排在前面, 游标分页依赖默认顺序, 不参与
+		items := []string{}
+		if terms := host.ParseKeywords(req.Keywords); len(terms) > 0 && !req.UseCursor {
+			items = append(items, exactMatchRank(terms)+" ASC")
+		}
+		// 每次调用Order都会生成一个ORDER BY, 多个排序字段需要拼接后只调用一次
+		items = append(items, "r.create_at DESC", "r.id")
+		query.Order(strings.Join(items, ", ")).Desc()
+		return
+	}
+
+	items := make([]string, 0, len(req.Sort)+1)
+	for _, sb := range req.Sort {
+		column, ok := sortColumns[sb.Field]
+		if !ok {
+			continue
+		}
+		if sb.Desc {
+			items = append(items, column+" DESC")
+		} else {
+			items = append(items, column+" ASC")
+		}
+	}
+	// 排序字段的值可能相同, 补充主键保证分页结果稳定
+	items = append(items, "r.id")
+	query.Order(strings.Join(items, ", ")).Asc()
+}
+
+// 把查询请求中的过滤条件翻译成Where语句
+func (i *impl) buildQueryFilter(query *sqlbuilder.Builder, req *host.QueryHostRequest) {
+	// 默认不包含已删除的主机, deleted=true 时只查询回收站
+	if req.Deleted {
+		query.Where("r.deleted_at > 0")
+	} else {
+		query.Where("r.deleted_at = 0")
+	}
+
+	// 用户输入了关键字, 按配置的搜索方式匹配多个字段
+	if terms := host.ParseKeywords(req.Keywords); len(terms) > 0 {
+		i.search.Where(query, terms)
+	}
+
+	// 标签过滤, 所有标签都需要匹配
+	for k, v := range req.Tags {
+		query.Where(tagFilterSQL, k, v)
+	}
+
+	if len(req.Vendor) > 0 {
+		vendors := make([]interface{}, 0, len(req.Vendor))
+		for _, v := range req.Vendor {
+			vendors = append(vendors, v)
+		}
+		query.Where(inStmt("r.vendor", len(vendors)), vendors...)
+	}
+	whereIn(query, "r.region", req.Region)
+	whereIn(query, "r.zone", req.Zone)
+	whereIn(query, "r.status", req.Status)
+	whereIn(query, "r.category", req.Category)
+	whereIn(query, "r.type", req.Type)
+	whereIn(query, "r.pay_type", req.PayType)
+	whereIn(query, "r.public_ip", req.PublicIP)
+	whereIn(query, "r.private_ip", req.PrivateIP)
+	// 同步账号使用确定性加密存储, 按加密后的值过滤
+	accounts := make([]string, 0, len(req.SyncAccount))
+	for _, a := range req.SyncAccount {
+		accounts = append(accounts, i.cipher.EncryptDeterministic(a))
+	}
+	whereIn(query, "r.sync_account", accounts)
+	whereIn(query, "h.os_type", req.OSType)
+
+	// 命名空间过滤, 同时限制在调用方可以访问的命名空间内
+	whereIn(query, "r.namespace", req.Namespace)
+	whereAllowed(query, req.AllowedNamespaces)
+
+	if req.CPUMin > 0 {
+		query.Where("h.cpu >= ?", req.CPUMin)
+	}
+	if req.CPUMax > 0 {
+		query.Where("h.cpu <= ?", req.CPUMax)
+	}
+	if req.MemoryMin > 0 {
+		query.Where("h.memory >= ?", req.MemoryMin)
+	}
+	if req.MemoryMax > 0 {
+		query.Where("h.memory <= ?", req.MemoryMax)
+	}
+
+	if req.DescribeChangedSince > 0 {
+		query.Where("r.describe_changed_at >= ?", req.DescribeChangedSince)
+	}
+
+	// 没有设置过期时间的主机(expire_at=0)不会过期
+	if req.ExpireBefore > 0 {
+		query.Where("r.expire_at > 0 AND r.expire_at <= ?", req.ExpireBefore)
+	}
+}
+
+// 字符串列表过滤: column IN (?,?)
+func whereIn(query *sqlbuilder.Builder, column string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	args := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	query.Where(inStmt(column, len(args)), args...)
+}
+
+// 限制在调用方可以访问的命名空间内, nil表示不限制, 空列表时不返回任何数据
+func whereAllowed(query *sqlbuilder.Builder, allowed []string) {
+	if allowed == nil {
+		return
+	}
+	if len(allowed) == 0 {
+		query.Where("1 = 0")
+		return
+	}
+	whereIn(query, "r.namespace", allowed)
+}
+
+func inStmt(column string, n int) string {
+	return fmt.Sprintf("%s IN (%s)", column, strings.TrimSuffix(strings.Repeat("?,", n), ","))
+}
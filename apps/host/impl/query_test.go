@@ -0,0 +1,47 @@
+package impl_test
+
+import (
+	"strings"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/impl"
+
+	"github.com/infraboard/mcube/sqlbuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildOrder(req *host.QueryHostRequest) string {
+	query := sqlbuilder.NewQuery("SELECT r.id FROM resource r")
+	impl.BuildQueryOrder(query, req)
+	sqlStr, _ := query.BuildQuery()
+	return sqlStr
+}
+
+func TestQueryOrder(t *testing.T) {
+	should := assert.New(t)
+
+	sqlStr := buildOrder(host.NewQueryHostRequest())
+	should.Equal(1, strings.Count(sqlStr, "ORDER BY"))
+	should.Contains(sqlStr, "ORDER BY r.create_at DESC, r.id DESC")
+}
+
+func TestQueryOrderWithSort(t *testing.T) {
+	should := assert.New(t)
+
+	req := host.NewQueryHostRequest()
+	req.Sort = []*host.SortBy{{Field: "cpu", Desc: true}, {Field: "name"}}
+	sqlStr := buildOrder(req)
+	should.Equal(1, strings.Count(sqlStr, "ORDER BY"))
+	should.Contains(sqlStr, "ORDER BY h.cpu DESC, r.name ASC, r.id ASC")
+}
+
+func TestQueryOrderWithKeywords(t *testing.T) {
+	should := assert.New(t)
+
+	req := host.NewQueryHostRequest()
+	req.Keywords = "host01"
+	sqlStr := buildOrder(req)
+	should.Equal(1, strings.Count(sqlStr, "ORDER BY"))
+	should.Regexp(`ORDER BY CASE WHEN .+ THEN 0 ELSE 1 END ASC, r\.create_at DESC, r\.id DESC`, sqlStr)
+}
@@ -0,0 +1,129 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+// 在写入主机的事务中保存一个新版本
+func (i *impl) saveRevision(ctx context.Context, tx *sql.Tx, rev *host.Revision) error {
+	if err := tx.QueryRowContext(ctx, nextRevisionSQL, rev.HostId).Scan(&rev.Revision); err != nil {
+		return fmt.Errorf("query host %s next revision error, %s", rev.HostId, err)
+	}
+
+	data, err := i.marshalRevision(rev.Host)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, insertRevisionSQL, rev.HostId, rev.Revision, rev.Action, rev.Operator, rev.CreateAt, data)
+	if err != nil {
+		return fmt.Errorf("insert host %s revision error, %s", rev.HostId, err)
+	}
+	return nil
+}
+
+func (i *impl) QueryHostRevision(ctx context.Context, req *host.QueryHostRevisionRequest) (*host.RevisionSet, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query host revision request error, %s", err)
+	}
+
+	query := sqlbuilder.NewQuery(queryRevisionSQL).
+		Where("resource_id = ?", req.Id).
+		Order("revision").Desc().
+		Limit(int64(req.Offset()), uint(req.PageSize))
+
+	sqlStr, args := query.BuildQuery()
+	i.log.Debugf("sql: %s, args: %v", sqlStr, args)
+
+	rows, err := i.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query host revision error, %s", err)
+	}
+	defer rows.Close()
+
+	set := host.NewRevisionSet()
+	for rows.Next() {
+		rev, err := i.scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		set.Add(rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	countStr, countArgs := query.BuildCount()
+	if err := i.db.QueryRowContext(ctx, countStr, countArgs...).Scan(&set.Total); err != nil {
+		return nil, fmt.Errorf("query host revision count error, %s", err)
+	}
+	return set, nil
+}
+
+func (i *impl) DescribeHostRevision(ctx context.Context, req *host.DescribeHostRevisionRequest) (*host.Revision, error) {
+	query := sqlbuilder.NewQuery(queryRevisionSQL).Where("resource_id = ?", req.Id)
+	if req.Revision > 0 {
+		query.Where("revision = ?", req.Revision)
+	} else {
+		query.Order("revision").Desc().Limit(0, 1)
+	}
+
+	sqlStr, args := query.BuildQuery()
+	i.log.Debugf("sql: %s, args: %v", sqlStr, args)
+
+	rev, err := i.scanRevision(i.db.QueryRowContext(ctx, sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewNotFound("host %s revision %d not found", req.Id, req.Revision)
+		}
+		return nil, fmt.Errorf("query host revision error, %s", err)
+	}
+	return rev, nil
+}
+
+func (i *impl) DiffHostRevision(ctx context.Context, req *host.DiffHostRevisionRequest) (*host.RevisionDiff, error) {
+	to, err := i.DescribeHostRevision(ctx, &host.DescribeHostRevisionRequest{Id: req.Id, Revision: req.To})
+	if err != nil {
+		return nil, err
+	}
+
+	fromRevision := req.From
+	if fromRevision == 0 {
+		fromRevision = to.Revision - 1
+	}
+	if fromRevision < 1 {
+		return nil, exception.NewBadRequest("host %s revision %d has no previous revision", req.Id, to.Revision)
+	}
+
+	from, err := i.DescribeHostRevision(ctx, &host.DescribeHostRevisionRequest{Id: req.Id, Revision: fromRevision})
+	if err != nil {
+		return nil, err
+	}
+	return host.NewRevisionDiff(from, to)
+}
+
+// 按 queryRevisionSQL 的字段顺序读取版本数据, 并解密敏感字段
+func (i *impl) scanRevision(row scanner) (*host.Revision, error) {
+	var (
+		rev  = &host.Revision{Host: host.NewDefaultHost()}
+		data string
+	)
+	if err := row.Scan(&rev.HostId, &rev.Revision, &rev.Action, &rev.Operator, &rev.CreateAt, &data); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), rev.Host); err != nil {
+		return nil, fmt.Errorf("unmarshal host %s revision %d error, %s", rev.HostId, rev.Revision, err)
+	}
+	if err := openHost(i.cipher, rev.Host); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
@@ -0,0 +1,100 @@
+package impl
+
+import (
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+var (
+	// 搜索字段对应的表字段
+	searchColumns = map[string]string{
+		"name":            "r.name",
+		"description":     "r.description",
+		"instance_id":     "r.instance_id",
+		"public_ip":       "r.public_ip",
+		"private_ip":      "r.private_ip",
+		"serial_number":   "h.serial_number",
+		"security_groups": "h.security_groups",
+	}
+
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	// 布尔模式下有特殊含义的字符
+	fulltextEscaper = strings.NewReplacer(`+`, ` `, `-`, ` `, `<`, ` `, `>`, ` `, `(`, ` `, `)`, ` `, `~`, ` `, `*`, ` `, `"`, ` `, `@`, ` `)
+)
+
+// 把搜索条件翻译成Where语句
+type searcher interface {
+	Where(query *sqlbuilder.Builder, terms []*host.SearchTerm)
+}
+
+func newSearcher(t conf.SearchType) (searcher, error) {
+	switch t {
+	case conf.LikeSearch, "":
+		return &likeSearcher{}, nil
+	case conf.FulltextSearch:
+		return &fulltextSearcher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown search type %s", t)
+	}
+}
+
+// 使用LIKE匹配, 每个条件的多个字段之间是或的关系
+type likeSearcher struct{}
+
+func (s *likeSearcher) Where(query *sqlbuilder.Builder, terms []*host.SearchTerm) {
+	for _, t := range terms {
+		likeWhere(query, t)
+	}
+}
+
+func likeWhere(query *sqlbuilder.Builder, t *host.SearchTerm) {
+	conds := make([]string, 0, len(t.Fields))
+	args := make([]interface{}, 0, len(t.Fields))
+	value := "%" + likeEscaper.Replace(t.Value) + "%"
+	for _, f := range t.Fields {
+		conds = append(conds, searchColumns[f]+" LIKE ?")
+		args = append(args, value)
+	}
+	query.Where("("+strings.Join(conds, " OR ")+")", args...)
+}
+
+// 使用FULLTEXT索引匹配, 依赖 ft_resource_search 和 ft_host_search 索引
+// 全文索引按分词匹配, 不适合IP这类前缀匹配, 带字段前缀的条件仍然使用LIKE
+type fulltextSearcher struct{}
+
+func (s *fulltextSearcher) Where(query *sqlbuilder.Builder, terms []*host.SearchTerm) {
+	for _, t := range terms {
+		against := strings.TrimSpace(fulltextEscaper.Replace(t.Value))
+		if t.Prefix != "" || against == "" {
+			likeWhere(query, t)
+			continue
+		}
+
+		// 短语整体匹配, 单词按前缀匹配, 被特殊字符分开的多个单词都需要匹配
+		if t.Phrase {
+			against = `"` + against + `"`
+		} else {
+			against = "+" + strings.Join(strings.Fields(against), "* +") + "*"
+		}
+		query.Where("(MATCH(r.name,r.description,r.instance_id,r.public_ip,r.private_ip) AGAINST(? IN BOOLEAN MODE) OR MATCH(h.serial_number,h.security_groups) AGAINST(? IN BOOLEAN MODE))",
+			against, against)
+	}
+}
+
+// 有字段和搜索内容完全相同的主机排在前面
+// sqlbuilder 的 Order 不支持占位符, 搜索内容使用十六进制字面量, 避免SQL注入
+func exactMatchRank(terms []*host.SearchTerm) string {
+	conds := []string{}
+	for _, t := range terms {
+		value := fmt.Sprintf("CONVERT(X'%x' USING utf8mb4)", t.Value)
+		for _, f := range t.Fields {
+			conds = append(conds, searchColumns[f]+"="+value)
+		}
+	}
+	return "CASE WHEN " + strings.Join(conds, " OR ") + " THEN 0 ELSE 1 END"
+}
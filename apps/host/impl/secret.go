@@ -0,0 +1,225 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/secret"
+)
+
+const (
+	rekeyPageSize = 500
+
+	queryResourceSecretSQL  = `SELECT id, sync_account FROM resource WHERE id > ? ORDER BY id LIMIT ?`
+	updateResourceSecretSQL = `UPDATE resource SET sync_account=? WHERE id=?`
+	queryHostSecretSQL      = `SELECT resource_id, key_pair_name FROM host WHERE resource_id > ? ORDER BY resource_id LIMIT ?`
+	updateHostSecretSQL     = `UPDATE host SET key_pair_name=? WHERE resource_id=?`
+	queryRevisionSecretSQL  = `SELECT resource_id, revision, data FROM host_revision WHERE (resource_id, revision) > (?, ?) ORDER BY resource_id, revision LIMIT ?`
+	updateRevisionSecretSQL = `UPDATE host_revision SET data=? WHERE resource_id=? AND revision=?`
+)
+
+// 返回敏感字段加密后的副本, 不修改原对象
+// SyncAccount 需要按账号过滤, 使用确定性加密, KeyPairName 不需要查询, 使用随机nonce加密
+func sealHost(c *secret.Cipher, ins *host.Host) (*host.Host, error) {
+	var (
+		sealed = *ins
+		res    = *ins.Resource
+		desc   = *ins.Describe
+		err    error
+	)
+	res.SyncAccount = c.EncryptDeterministic(res.SyncAccount)
+	if desc.KeyPairName, err = c.Encrypt(desc.KeyPairName); err != nil {
+		return nil, err
+	}
+	sealed.Resource, sealed.Describe = &res, &desc
+	return &sealed, nil
+}
+
+// 解密读取出来的敏感字段
+func openHost(c *secret.Cipher, ins *host.Host) (err error) {
+	if ins.SyncAccount, err = c.Decrypt(ins.SyncAccount); err != nil {
+		return fmt.Errorf("decrypt host %s sync_account error, %s", ins.Id, err)
+	}
+	if ins.KeyPairName, err = c.Decrypt(ins.KeyPairName); err != nil {
+		return fmt.Errorf("decrypt host %s key_pair_name error, %s", ins.Id, err)
+	}
+	return nil
+}
+
+// 版本中保存的是主机的完整快照, 敏感字段同样加密
+func (i *impl) marshalRevision(ins *host.Host) (string, error) {
+	sealed, err := sealHost(i.cipher, ins)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// 密钥轮换的结果, 各个表重新加密的行数
+type RekeyResult struct {
+	Resource int `json:"resource"`
+	Host     int `json:"host"`
+	Revision int `json:"revision"`
+}
+
+// 把当前密钥加密的数据使用新的密钥重新加密, 加密之前写入的明文数据同时被加密
+// 逐行更新, 中途失败后可以重新执行, 已经使用新密钥加密的数据会被跳过
+// 执行期间其他使用旧密钥的服务实例写入的数据无法被新密钥解密, 需要先停止服务
+func (i *impl) Rekey(ctx context.Context, to *secret.Cipher) (*RekeyResult, error) {
+	result := &RekeyResult{}
+
+	// resource.sync_account
+	err := i.rekeyColumn(ctx, queryResourceSecretSQL, updateResourceSecretSQL, func(v string) (string, error) {
+		plain, err := i.reopen(to, v)
+		if err != nil {
+			return "", err
+		}
+		return to.EncryptDeterministic(plain), nil
+	}, &result.Resource)
+	if err != nil {
+		return result, fmt.Errorf("rekey resource error, %s", err)
+	}
+
+	// host.key_pair_name
+	err = i.rekeyColumn(ctx, queryHostSecretSQL, updateHostSecretSQL, func(v string) (string, error) {
+		plain, err := i.reopen(to, v)
+		if err != nil {
+			return "", err
+		}
+		return to.Encrypt(plain)
+	}, &result.Host)
+	if err != nil {
+		return result, fmt.Errorf("rekey host error, %s", err)
+	}
+
+	if err := i.rekeyRevision(ctx, to, &result.Revision); err != nil {
+		return result, fmt.Errorf("rekey host revision error, %s", err)
+	}
+	return result, nil
+}
+
+// 先使用当前密钥解密, 失败时说明已经使用新密钥加密过
+func (i *impl) reopen(to *secret.Cipher, v string) (string, error) {
+	plain, err := i.cipher.Decrypt(v)
+	if err == nil {
+		return plain, nil
+	}
+	return to.Decrypt(v)
+}
+
+// 按主键分页重新加密一列数据, 值没有变化的行不更新
+func (i *impl) rekeyColumn(ctx context.Context, querySQL, updateSQL string, reseal func(string) (string, error), count *int) error {
+	last := ""
+	for {
+		rows, err := i.db.QueryContext(ctx, querySQL, last, rekeyPageSize)
+		if err != nil {
+			return err
+		}
+
+		type item struct{ id, value string }
+		items := []item{}
+		for rows.Next() {
+			it := item{}
+			if err := rows.Scan(&it.id, &it.value); err != nil {
+				rows.Close()
+				return err
+			}
+			items = append(items, it)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, it := range items {
+			if it.value == "" {
+				continue
+			}
+			sealed, err := reseal(it.value)
+			if err != nil {
+				return fmt.Errorf("id %s, %s", it.id, err)
+			}
+			if _, err := i.db.ExecContext(ctx, updateSQL, sealed, it.id); err != nil {
+				return err
+			}
+			*count++
+		}
+
+		if len(items) < rekeyPageSize {
+			return nil
+		}
+		last = items[len(items)-1].id
+	}
+}
+
+// 重新加密版本快照中的敏感字段
+func (i *impl) rekeyRevision(ctx context.Context, to *secret.Cipher, count *int) error {
+	var (
+		lastId  = ""
+		lastRev = 0
+	)
+	for {
+		rows, err := i.db.QueryContext(ctx, queryRevisionSecretSQL, lastId, lastRev, rekeyPageSize)
+		if err != nil {
+			return err
+		}
+
+		revs := []*host.Revision{}
+		for rows.Next() {
+			var (
+				rev  = &host.Revision{Host: host.NewDefaultHost()}
+				data string
+			)
+			if err := rows.Scan(&rev.HostId, &rev.Revision, &data); err != nil {
+				rows.Close()
+				return err
+			}
+			if err := json.Unmarshal([]byte(data), rev.Host); err != nil {
+				rows.Close()
+				return fmt.Errorf("unmarshal host %s revision %d error, %s", rev.HostId, rev.Revision, err)
+			}
+			revs = append(revs, rev)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, rev := range revs {
+			if err := i.rekeyHost(to, rev.Host); err != nil {
+				return fmt.Errorf("host %s revision %d, %s", rev.HostId, rev.Revision, err)
+			}
+			sealed, err := sealHost(to, rev.Host)
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(sealed)
+			if err != nil {
+				return err
+			}
+			if _, err := i.db.ExecContext(ctx, updateRevisionSecretSQL, string(data), rev.HostId, rev.Revision); err != nil {
+				return err
+			}
+			*count++
+		}
+
+		if len(revs) < rekeyPageSize {
+			return nil
+		}
+		lastId, lastRev = revs[len(revs)-1].HostId, revs[len(revs)-1].Revision
+	}
+}
+
+// 使用任意一个密钥解密主机的敏感字段
+func (i *impl) rekeyHost(to *secret.Cipher, ins *host.Host) error {
+	if err := openHost(i.cipher, ins); err == nil {
+		return nil
+	}
+	return openHost(to, ins)
+}
@@ -17,15 +17,17 @@ const (
 		status,
 		update_at,
 		sync_at,
-		accout,
+		sync_account,
 		public_ip,
-		private_ip
+		private_ip,
 		pay_type,
-		describe_bash,
-		resource_bash
+		resource_hash,
+		describe_hash,
+		describe_changed_at,
+		namespace
 	)
 	VALUES
-		(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
+		(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
 	`
 	// INSERT INTO `host` ( resource_id, cpu, memory, gpu_amount, gpu_spec, os_type, os_name, serial_number )
 	// VALUES
@@ -35,13 +37,59 @@ const (
 	VALUES
 		( ?,?,?,?,?,?,?,?,?,?,?,?,? );
 	`
-	queryHostSQL = `SELECT * FROM resource as r LEFT JOIN host h ON r.id=h.resource_id`
+	// 字段顺序和 scanHost 保持一致
+	queryHostSQL = `SELECT r.id,r.namespace,r.vendor,r.region,r.zone,r.create_at,r.expire_at,r.category,r.type,r.instance_id,r.name,r.description,r.status,r.update_at,r.sync_at,r.sync_account,r.public_ip,r.private_ip,r.pay_type,r.resource_hash,r.describe_hash,r.describe_changed_at,r.deleted_at,r.deleted_by,r.version,h.cpu,h.memory,h.gpu_amount,h.gpu_spec,h.os_type,h.os_name,h.serial_number,h.image_id,h.internet_max_bandwidth_out,h.internet_max_bandwidth_in,h.key_pair_name,h.security_groups FROM resource as r LEFT JOIN host h ON r.id=h.resource_id`
 
-	updateResourceSQL = `UPDATE resource SET vendor=?,region=?,zone=?,expire_at=?,name=?,descruotion=? WHERE id = ?`
+	// 分组统计, %s 为分组字段
+	statsHostSQL = `SELECT %s COUNT(*),COALESCE(SUM(h.cpu),0),COALESCE(SUM(h.memory),0),COALESCE(SUM(h.gpu_amount),0) FROM resource as r LEFT JOIN host h ON r.id=h.resource_id`
 
-	updateHostSQL = `UPDATE host SET cpu=?,memory=? WHERE resource_id = ?`
+	// 乐观锁: 只有版本号匹配时才更新, 同时版本号加1
+	updateResourceSQL = `UPDATE resource SET namespace=?,vendor=?,region=?,zone=?,expire_at=?,category=?,type=?,instance_id=?,name=?,description=?,status=?,update_at=?,sync_at=?,sync_account=?,public_ip=?,private_ip=?,pay_type=?,resource_hash=?,describe_hash=?,describe_changed_at=?,version=version+1 WHERE id = ? AND version = ?`
+	// 内容没有变化时只刷新同步时间, 不修改版本号
+	updateSyncAtSQL = `UPDATE resource SET sync_at=? WHERE id = ?`
 
-	deleteResourceSQL = `DELETE FROM resource WHERE id=?`
+	updateHostSQL = `UPDATE host SET cpu=?,memory=?,gpu_amount=?,gpu_spec=?,os_type=?,os_name=?,serial_number=?,image_id=?,internet_max_bandwidth_out=?,internet_max_bandwidth_in=?,key_pair_name=?,security_groups=? WHERE resource_id = ?`
 
-	deleteHostSQL = `DELETE FROM host WHERE resource_id=?`
+	// 软删除, 只标记删除时间和删除人
+	deleteResourceSQL = `UPDATE resource SET deleted_at=?,deleted_by=?,version=version+1 WHERE id=? AND deleted_at=0 AND version=?`
+
+	restoreResourceSQL = `UPDATE resource SET deleted_at=0,deleted_by='',version=version+1 WHERE id=? AND deleted_at>0`
+
+	// 彻底清除超过保留期的已删除主机, 先清除关联表, 最后清除resource表
+	purgeRevisionSQL = `DELETE FROM host_revision WHERE resource_id IN (SELECT id FROM resource WHERE deleted_at>0 AND deleted_at<?)`
+	purgeTagSQL      = `DELETE FROM resource_tag WHERE resource_id IN (SELECT id FROM resource WHERE deleted_at>0 AND deleted_at<?)`
+	purgeHostSQL     = `DELETE FROM host WHERE resource_id IN (SELECT id FROM resource WHERE deleted_at>0 AND deleted_at<?)`
+	purgeResourceSQL = `DELETE FROM resource WHERE deleted_at>0 AND deleted_at<?`
+
+	insertTagSQL = `INSERT INTO resource_tag (resource_id, t_key, t_value) VALUES (?,?,?)`
+
+	queryTagSQL = `SELECT resource_id, t_key, t_value FROM resource_tag`
+
+	deleteTagSQL = `DELETE FROM resource_tag WHERE resource_id=?`
+
+	// 标签过滤条件, 每个标签一个子查询
+	tagFilterSQL = `r.id IN (SELECT resource_id FROM resource_tag WHERE t_key = ? AND t_value = ?)`
+)
+
+const (
+	// 批量录入使用多行INSERT语句, VALUES 部分按行数拼接
+	batchInsertResourceSQL = `INSERT INTO resource (id,vendor,region,zone,create_at,expire_at,category,type,instance_id,name,description,status,update_at,sync_at,sync_account,public_ip,private_ip,pay_type,resource_hash,describe_hash,describe_changed_at,namespace) VALUES `
+
+	batchInsertDescribeSQL = `INSERT INTO host (resource_id,cpu,memory,gpu_amount,gpu_spec,os_type,os_name,serial_number,image_id,internet_max_bandwidth_out,internet_max_bandwidth_in,key_pair_name,security_groups) VALUES `
+
+	batchInsertTagSQL = `INSERT INTO resource_tag (resource_id, t_key, t_value) VALUES `
+
+	// 每条多行INSERT语句包含的最大行数, 避免超过max_allowed_packet和占位符数量限制
+	batchChunkSize = 200
+)
+
+const (
+	// 在事务中锁定主机的版本记录, 避免并发写入产生相同的版本号
+	nextRevisionSQL = `SELECT COALESCE(MAX(revision),0)+1 FROM host_revision WHERE resource_id=? FOR UPDATE`
+
+	insertRevisionSQL = `INSERT INTO host_revision (resource_id, revision, action, operator, create_at, data) VALUES (?,?,?,?,?,?)`
+
+	batchInsertRevisionSQL = `INSERT INTO host_revision (resource_id, revision, action, operator, create_at, data) VALUES `
+
+	queryRevisionSQL = `SELECT resource_id, revision, action, operator, create_at, data FROM host_revision`
 )
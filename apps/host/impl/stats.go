@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+var (
+	// 分组字段对应的表字段, host表的数据可能不存在, 统一转换为空字符串
+	groupColumns = map[string]string{
+		"namespace": "r.namespace",
+		"vendor":    "r.vendor",
+		"region":    "r.region",
+		"zone":      "r.zone",
+		"status":    "r.status",
+		"pay_type":  "r.pay_type",
+		"os_type":   "COALESCE(h.os_type,'')",
+	}
+)
+
+func (i *impl) StatsHost(ctx context.Context, req *host.StatsHostRequest) (*host.StatsSet, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate stats host request error, %s", err)
+	}
+
+	columns := make([]string, 0, len(req.GroupBy))
+	for _, f := range req.GroupBy {
+		columns = append(columns, groupColumns[f])
+	}
+
+	query := sqlbuilder.NewQuery(fmt.Sprintf(statsHostSQL, selectPrefix(columns)))
+	i.buildQueryFilter(query, req.QueryHostRequest)
+	if len(columns) > 0 {
+		query.GroupBy(strings.Join(columns, ","))
+	}
+
+	sqlStr, args := query.BuildQuery()
+	i.log.Debugf("sql: %s, args: %v", sqlStr, args)
+
+	rows, err := i.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query host stats error, %s", err)
+	}
+	defer rows.Close()
+
+	set := host.NewStatsSet(req.GroupBy)
+	for rows.Next() {
+		values := make([]string, len(columns))
+		item := &host.HostStats{}
+		dest := make([]interface{}, 0, len(columns)+4)
+		for idx := range values {
+			dest = append(dest, &values[idx])
+		}
+		dest = append(dest, &item.Count, &item.CPU, &item.Memory, &item.GPUAmount)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		// 不分组时, 没有数据也会返回一行0值
+		if len(columns) == 0 {
+			set.Add(item)
+			continue
+		}
+		item.Group = map[string]string{}
+		for idx, f := range req.GroupBy {
+			item.Group[f] = values[idx]
+			if f == "vendor" {
+				n, _ := strconv.Atoi(values[idx])
+				item.Group[f] = host.Vendor(n).String()
+			}
+		}
+		set.Add(item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	set.Sort()
+	return set, nil
+}
+
+// 分组字段放在统计字段前面
+func selectPrefix(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return strings.Join(columns, ",") + ","
+}
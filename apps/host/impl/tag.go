@@ -0,0 +1,74 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 把主机的标签全量写入resource_tag表, 已有的标签会先被清除
+// PUT 和 PATCH 在对象层面已经计算出最终的标签, 这里只需要全量覆盖
+func (i *impl) saveTags(ctx context.Context, tx *sql.Tx, ins *host.Host) error {
+	if _, err := tx.ExecContext(ctx, deleteTagSQL, ins.Id); err != nil {
+		return fmt.Errorf("delete host %s tags error, %s", ins.Id, err)
+	}
+
+	if len(ins.Tags) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, insertTagSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for k, v := range ins.Tags {
+		if _, err := stmt.ExecContext(ctx, ins.Id, k, v); err != nil {
+			return fmt.Errorf("insert host %s tag %s error, %s", ins.Id, k, err)
+		}
+	}
+	return nil
+}
+
+// 批量查询主机的标签, 并补充到主机对象上
+func (i *impl) loadTags(ctx context.Context, hosts ...*host.Host) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	index := make(map[string]*host.Host, len(hosts))
+	placeholders := make([]string, 0, len(hosts))
+	args := make([]interface{}, 0, len(hosts))
+	for _, ins := range hosts {
+		index[ins.Id] = ins
+		placeholders = append(placeholders, "?")
+		args = append(args, ins.Id)
+	}
+
+	sqlStr := fmt.Sprintf("%s WHERE resource_id IN (%s)", queryTagSQL, strings.Join(placeholders, ","))
+	rows, err := i.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return fmt.Errorf("query tags error, %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, k, v string
+		if err := rows.Scan(&id, &k, &v); err != nil {
+			return err
+		}
+		ins, ok := index[id]
+		if !ok {
+			continue
+		}
+		if ins.Tags == nil {
+			ins.Tags = map[string]string{}
+		}
+		ins.Tags[k] = v
+	}
+	return rows.Err()
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 把单元格的值写入主机的字段, 值已经去掉首尾空白, 空值不会调用
+type setter func(h *host.Host, v string) error
+
+var (
+	// 可以导入的字段, 名称和主机的JSON字段名相同, 和导出的列一致
+	fields = map[string]setter{
+		"namespace": func(h *host.Host, v string) error { h.Namespace = v; return nil },
+		"vendor": func(h *host.Host, v string) (err error) {
+			h.Vendor, err = host.ParseVendor(v)
+			return
+		},
+		"region":      func(h *host.Host, v string) error { h.Region = v; return nil },
+		"zone":        func(h *host.Host, v string) error { h.Zone = v; return nil },
+		"create_at":   func(h *host.Host, v string) (err error) { h.CreateAt, err = parseTime(v); return },
+		"expire_at":   func(h *host.Host, v string) (err error) { h.ExpireAt, err = parseTime(v); return },
+		"category":    func(h *host.Host, v string) error { h.Category = v; return nil },
+		"type":        func(h *host.Host, v string) error { h.Type = v; return nil },
+		"instance_id": func(h *host.Host, v string) error { h.InstanceId = v; return nil },
+		"name":        func(h *host.Host, v string) error { h.Name = v; return nil },
+		"description": func(h *host.Host, v string) error { h.Description = v; return nil },
+		"status":      func(h *host.Host, v string) error { h.Status = v; return nil },
+		"tags": func(h *host.Host, v string) (err error) {
+			h.Tags, err = parseTags(v)
+			return
+		},
+		"sync_account":               func(h *host.Host, v string) error { h.SyncAccount = v; return nil },
+		"public_ip":                  func(h *host.Host, v string) error { h.PublicIP = v; return nil },
+		"private_ip":                 func(h *host.Host, v string) error { h.PrivateIP = v; return nil },
+		"pay_type":                   func(h *host.Host, v string) error { h.PayType = v; return nil },
+		"cpu":                        func(h *host.Host, v string) (err error) { h.CPU, err = strconv.Atoi(v); return },
+		"memory":                     func(h *host.Host, v string) (err error) { h.Memory, err = strconv.Atoi(v); return },
+		"gpu_amount":                 func(h *host.Host, v string) (err error) { h.GPUAmount, err = strconv.Atoi(v); return },
+		"gpu_spec":                   func(h *host.Host, v string) error { h.GPUSpec = v; return nil },
+		"os_type":                    func(h *host.Host, v string) error { h.OSType = v; return nil },
+		"os_name":                    func(h *host.Host, v string) error { h.OSName = v; return nil },
+		"serial_number":              func(h *host.Host, v string) error { h.SerialNumber = v; return nil },
+		"image_id":                   func(h *host.Host, v string) error { h.ImageID = v; return nil },
+		"internet_max_bandwidth_out": func(h *host.Host, v string) (err error) { h.InternetMaxBandwidthOut, err = strconv.Atoi(v); return },
+		"internet_max_bandwidth_in":  func(h *host.Host, v string) (err error) { h.InternetMaxBandwidthIn, err = strconv.Atoi(v); return },
+		"key_pair_name":              func(h *host.Host, v string) error { h.KeyPairName = v; return nil },
+		"security_groups":            func(h *host.Host, v string) error { h.SecurityGroups = v; return nil },
+	}
+
+	// 由服务端维护的字段, 导出的文件中包含这些列, 导入时忽略
+	ignoredFields = map[string]bool{
+		"id":        true,
+		"update_at": true,
+		"sync_at":   true,
+	}
+
+	timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "2006-01-02"}
+)
+
+// 列名转换成字段名: "Public IP" --> public_ip
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// 时间支持13位时间戳和导出时使用的日期格式, 日期按本地时区解析
+func parseTime(v string) (int64, error) {
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return ts, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t.UnixNano() / 1000000, nil
+		}
+	}
+	return 0, fmt.Errorf("time %s format must be a 13 digit timestamp or 2006-01-02 15:04:05", v)
+}
+
+// 标签格式和导出的一致: k1=v1,k2=v2
+func parseTags(v string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, item := range strings.Split(v, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("tag %s format must be key=value", item)
+		}
+		tags[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return tags, nil
+}
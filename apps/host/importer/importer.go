@@ -0,0 +1,350 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+type Mode string
+
+const (
+	// 全部作为新主机录入
+	CreateMode = Mode("create")
+	// 按厂商和实例Id录入或者更新, 要求有instance_id列
+	UpsertMode = Mode("upsert")
+)
+
+const (
+	DefaultBatchSize = 100
+	// 报告中最多记录的错误数, 超过后只计数
+	maxErrors = 1000
+	// 映射到该字段名的列会被忽略
+	skipField = "-"
+)
+
+func NewImportRequest() *ImportRequest {
+	return &ImportRequest{
+		Mapping:   map[string]string{},
+		Mode:      CreateMode,
+		BatchSize: DefaultBatchSize,
+		Namespace: host.DefaultNamespace,
+	}
+}
+
+// 从CSV文件导入主机, 第一行为表头
+type ImportRequest struct {
+	// CSV列名 --> 主机字段名, 没有指定的列按列名匹配字段
+	Mapping map[string]string
+	Mode    Mode
+	// 只校验数据, 报告每一行的错误, 不写入
+	DryRun bool
+	// 每批写入的行数
+	BatchSize int
+	// 操作人, 更新已有主机时记录到历史版本中
+	Operator string
+	// 没有namespace列或者该列为空时, 主机录入到该命名空间
+	Namespace string
+	// 校验每一行的命名空间, 比如是否存在、是否有权限, 为空时不校验
+	CheckNamespace func(ctx context.Context, namespace string) error
+	// 调用方可以访问的命名空间, nil表示不限制, 更新已有主机时透传给UpsertHost
+	AllowedNamespaces []string
+}
+
+func (req *ImportRequest) Validate() error {
+	if req.Mode != CreateMode && req.Mode != UpsertMode {
+		return fmt.Errorf("import mode %s not supported, supported: create, upsert", req.Mode)
+	}
+	if req.BatchSize <= 0 || req.BatchSize > host.MaxBatchSize {
+		return fmt.Errorf("batch_size must between 1 and %d", host.MaxBatchSize)
+	}
+	return nil
+}
+
+// 解析列映射, 格式: <列名>=<字段名>, 字段名为"-"表示忽略该列
+func ParseMapping(items []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, item := range items {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("mapping %s format must be <column>=<field>", item)
+		}
+		m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+// 导入的结果
+type Report struct {
+	DryRun bool `json:"dry_run"`
+	// 数据行数, 不包括表头
+	Total int `json:"total"`
+	// 校验通过的行数, dry run时即可以导入的行数
+	Valid     int         `json:"valid"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Failed    int         `json:"failed"`
+	Errors    []*RowError `json:"errors"`
+}
+
+func (r *Report) addError(line int, err error) {
+	r.Failed++
+	if len(r.Errors) < maxErrors {
+		r.Errors = append(r.Errors, &RowError{Line: line, Error: err.Error()})
+	}
+}
+
+// 行级别的错误
+type RowError struct {
+	// 在文件中的行号, 表头为第1行
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// 表头中的一列
+type column struct {
+	name  string
+	field string
+	set   setter
+}
+
+type row struct {
+	line int
+	host *host.Host
+}
+
+// 按批次读取并写入, 内存中最多只保留一批数据
+// 参数或者表头不合法时返回错误, 数据行的错误记录在报告中, 不影响其他行
+func Import(ctx context.Context, svc host.Service, r io.Reader, req *ImportRequest) (*Report, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header error, %s", err)
+	}
+	cols, err := resolveHeader(header, req)
+	if err != nil {
+		return nil, err
+	}
+
+	im := &importer{
+		svc:        svc,
+		req:        req,
+		cols:       cols,
+		report:     &Report{DryRun: req.DryRun, Errors: []*RowError{}},
+		batch:      make([]*row, 0, req.BatchSize),
+		entries:    map[string]int{},
+		namespaces: map[string]error{},
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		im.report.Total++
+		if err != nil {
+			// 列数不一致只影响当前行, 其他格式错误无法继续解析
+			pe := &csv.ParseError{}
+			if !errors.As(err, &pe) || !errors.Is(err, csv.ErrFieldCount) {
+				im.report.addError(pe.StartLine, fmt.Errorf("csv format error, stop importing the rest lines, %s", err))
+				break
+			}
+			im.report.addError(pe.StartLine, fmt.Errorf("expect %d columns, but got %d", len(header), len(record)))
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		ins, err := im.parse(ctx, line, record)
+		if err != nil {
+			im.report.addError(line, err)
+			continue
+		}
+		im.report.Valid++
+		if req.DryRun {
+			continue
+		}
+
+		im.batch = append(im.batch, &row{line: line, host: ins})
+		if len(im.batch) >= req.BatchSize {
+			if err := im.flush(ctx); err != nil {
+				return im.report, err
+			}
+		}
+	}
+	if err := im.flush(ctx); err != nil {
+		return im.report, err
+	}
+	return im.report, nil
+}
+
+// 解析表头, 确定每一列对应的字段
+func resolveHeader(header []string, req *ImportRequest) ([]*column, error) {
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\xEF\xBB\xBF")
+	}
+
+	var (
+		cols    = make([]*column, len(header))
+		used    = map[string]string{}
+		unknown = []string{}
+	)
+	for idx, name := range header {
+		field, ok := req.Mapping[name]
+		if !ok {
+			field = normalize(name)
+		}
+		if field == skipField || ignoredFields[field] {
+			continue
+		}
+
+		set, ok := fields[field]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if other, ok := used[field]; ok {
+			return nil, fmt.Errorf("column %s and %s are both mapped to field %s", other, name, field)
+		}
+		used[field] = name
+		cols[idx] = &column{name: name, field: field, set: set}
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown columns: %s, map them to host fields or to %s to ignore",
+			strings.Join(unknown, ","), skipField)
+	}
+	if _, ok := used["instance_id"]; !ok && req.Mode == UpsertMode {
+		return nil, fmt.Errorf("upsert mode requires an instance_id column")
+	}
+	return cols, nil
+}
+
+type importer struct {
+	svc    host.Service
+	req    *ImportRequest
+	cols   []*column
+	report *Report
+	batch  []*row
+	// 文件中已经出现过的厂商+实例Id --> 行号
+	entries map[string]int
+	// 命名空间的校验结果, 每个命名空间只校验一次
+	namespaces map[string]error
+}
+
+// 解析并校验一行数据
+func (im *importer) parse(ctx context.Context, line int, record []string) (*host.Host, error) {
+	ins := host.NewDefaultHost()
+	if im.req.Mode == UpsertMode {
+		// 更新已有主机时保留原来的创建时间
+		ins = host.NewUpsertHostRequest().Host
+	}
+	ins.Namespace = im.req.Namespace
+
+	for idx, c := range im.cols {
+		v := strings.TrimSpace(record[idx])
+		if c == nil || v == "" {
+			continue
+		}
+		if err := c.set(ins, v); err != nil {
+			return nil, fmt.Errorf("column %s invalid, %s", c.name, err)
+		}
+	}
+
+	// Id在写入时由服务端生成
+	if err := ins.ValidateWithoutId(); err != nil {
+		return nil, err
+	}
+	if err := im.checkNamespace(ctx, ins.Namespace); err != nil {
+		return nil, err
+	}
+
+	if ins.InstanceId == "" {
+		if im.req.Mode == UpsertMode {
+			return nil, fmt.Errorf("instance_id required")
+		}
+		return ins, nil
+	}
+	key := host.NewDescribeHostRequestWithInstance(ins.Vendor, ins.InstanceId).Key()
+	if first, ok := im.entries[key]; ok {
+		return nil, fmt.Errorf("instance %s duplicated with line %d", key, first)
+	}
+	im.entries[key] = line
+	return ins, nil
+}
+
+func (im *importer) checkNamespace(ctx context.Context, namespace string) error {
+	if im.req.CheckNamespace == nil {
+		return nil
+	}
+	err, ok := im.namespaces[namespace]
+	if !ok {
+		err = im.req.CheckNamespace(ctx, namespace)
+		im.namespaces[namespace] = err
+	}
+	return err
+}
+
+// 写入当前批次
+func (im *importer) flush(ctx context.Context) error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer func() { im.batch = im.batch[:0] }()
+
+	if im.req.Mode == UpsertMode {
+		for _, r := range im.batch {
+			req := &host.UpsertHostRequest{Host: r.host, UpdateBy: im.req.Operator, AllowedNamespaces: im.req.AllowedNamespaces}
+			resp, err := im.svc.UpsertHost(ctx, req)
+			switch {
+			case err != nil:
+				im.report.addError(r.line, err)
+			case resp.Created:
+				im.report.Created++
+			case resp.Unchanged:
+				im.report.Unchanged++
+			default:
+				im.report.Updated++
+			}
+		}
+		return nil
+	}
+
+	req := host.NewBatchCreateHostRequest()
+	for _, r := range im.batch {
+		r.host.CreateBy = im.req.Operator
+		req.Items = append(req.Items, r.host)
+	}
+	resp, err := im.svc.BatchCreateHost(ctx, req)
+	if err != nil {
+		for _, r := range im.batch {
+			im.report.addError(r.line, err)
+		}
+		return nil
+	}
+	sort.Slice(resp.Items, func(i, j int) bool { return resp.Items[i].Index < resp.Items[j].Index })
+	for _, item := range resp.Items {
+		if item.Success {
+			im.report.Created++
+			continue
+		}
+		im.report.addError(im.batch[item.Index].line, errors.New(item.Error))
+	}
+	return nil
+}
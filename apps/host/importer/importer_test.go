@@ -0,0 +1,59 @@
+package importer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/importer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCSV = `Host Name,region,type,cpu,memory,instance_id,tags,Owner
+host01,hangzhou,sm1,1,2048,i-001,env=prod,alice
+host02,hangzhou,sm1,x,2048,i-002,,bob
+host03,,sm1,1,2048,i-003,,carol
+host04,hangzhou,sm1,2,4096,i-001,,dave
+`
+
+func TestImport(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	req := importer.NewImportRequest()
+	_, err := importer.Import(ctx, memory.Service, strings.NewReader(testCSV), req)
+	should.Error(err, "unknown columns should be rejected")
+
+	req.Mapping = map[string]string{"Host Name": "name", "Owner": "-"}
+	req.DryRun = true
+	report, err := importer.Import(ctx, memory.Service, strings.NewReader(testCSV), req)
+	if should.NoError(err) {
+		should.Equal(4, report.Total)
+		should.Equal(1, report.Valid)
+		should.Equal(3, report.Failed)
+		should.Equal(3, report.Errors[0].Line)
+		should.Equal(0, report.Created)
+	}
+
+	req.DryRun = false
+	req.Mode = importer.UpsertMode
+	report, err = importer.Import(ctx, memory.Service, strings.NewReader(testCSV), req)
+	if should.NoError(err) {
+		should.Equal(1, report.Created)
+	}
+	report, err = importer.Import(ctx, memory.Service, strings.NewReader(testCSV), req)
+	if should.NoError(err) {
+		should.Equal(0, report.Created)
+		should.Equal(1, report.Unchanged)
+	}
+
+	set, err := memory.Service.QueryHost(ctx, host.NewQueryHostRequest())
+	if should.NoError(err) && should.Len(set.Items, 1) {
+		should.Equal("host01", set.Items[0].Name)
+		should.Equal("prod", set.Items[0].Tags["env"])
+	}
+}
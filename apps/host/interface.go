@@ -1,18 +1,106 @@
 package host
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 type Service interface {
 	// 录入主机信息
 	CreateHost(context.Context, *Host) (*Host, error)
+	// 批量录入主机信息
+	BatchCreateHost(context.Context, *BatchCreateHostRequest) (*BatchCreateHostResponse, error)
 	// 查询主机列表信息
 	QueryHost(context.Context, *QueryHostRequest) (*Set, error)
+	// 按字段分组统计主机数量和资源总量
+	StatsHost(context.Context, *StatsHostRequest) (*StatsSet, error)
 	// 主机详情查询
 	DesribeHost(context.Context, *DesribeHostRequest) (*Host, error)
 	// 主机信息修改
 	UpdateHost(context.Context, *UpdateHostRequest) (*Host, error)
+	// 按厂商和实例Id录入主机, 已经存在时全量更新
+	UpsertHost(context.Context, *UpsertHostRequest) (*UpsertHostResponse, error)
 	// 删除主机 GRPC, delete event system
+	// 软删除, 删除后的主机进入回收站
 	DeleteHost(context.Context, *DeleteHostRequest) (*Host, error)
+	// 从回收站恢复主机
+	RestoreHost(context.Context, *RestoreHostRequest) (*Host, error)
+	// 彻底清除回收站中超过保留期的主机
+	PurgeHost(context.Context, *PurgeHostRequest) (*PurgeHostResponse, error)
+	// 查询主机的历史版本, 按版本号倒序
+	QueryHostRevision(context.Context, *QueryHostRevisionRequest) (*RevisionSet, error)
+	// 查询主机的某个历史版本
+	DescribeHostRevision(context.Context, *DescribeHostRevisionRequest) (*Revision, error)
+	// 对比主机两个版本之间的字段差异
+	DiffHostRevision(context.Context, *DiffHostRevisionRequest) (*RevisionDiff, error)
+}
+
+const (
+	// 单次批量录入的最大条数
+	MaxBatchSize = 1000
+)
+
+func NewBatchCreateHostRequest() *BatchCreateHostRequest {
+	return &BatchCreateHostRequest{
+		Items: []*Host{},
+	}
+}
+
+// 批量录入主机
+type BatchCreateHostRequest struct {
+	Items []*Host `json:"items"`
+	// 为true时, 任意一条失败则全部不录入
+	AllOrNothing bool `json:"all_or_nothing"`
+}
+
+func (req *BatchCreateHostRequest) Validate() error {
+	if len(req.Items) == 0 {
+		return fmt.Errorf("items required")
+	}
+	if len(req.Items) > MaxBatchSize {
+		return fmt.Errorf("items too many, max batch size is %d", MaxBatchSize)
+	}
+	return nil
+}
+
+func NewBatchCreateHostResponse() *BatchCreateHostResponse {
+	return &BatchCreateHostResponse{
+		Items: []*BatchCreateHostResult{},
+	}
+}
+
+// 批量录入的结果, 和请求中的Items一一对应
+type BatchCreateHostResponse struct {
+	SuccessCount int                      `json:"success_count"`
+	FailedCount  int                      `json:"failed_count"`
+	Items        []*BatchCreateHostResult `json:"items"`
+}
+
+func (resp *BatchCreateHostResponse) Add(item *BatchCreateHostResult) {
+	if item.Success {
+		resp.SuccessCount++
+	} else {
+		resp.FailedCount++
+	}
+	resp.Items = append(resp.Items, item)
+}
+
+// 单条主机的录入结果
+type BatchCreateHostResult struct {
+	// 在请求Items中的位置, 从0开始
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	Id      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func NewQueryHostRequest() *QueryHostRequest {
+	return &QueryHostRequest{
+		PageSize:   20,
+		PageNumber: 1,
+		Tags:       map[string]string{},
+		WithTotal:  true,
+	}
 }
 
 // 查询数据
@@ -20,6 +108,81 @@ type QueryHostRequest struct {
 	PageSize   int
 	PageNumber int
 	Keywords   string
+	// 标签过滤, 所有标签都匹配才返回, 对应query string: tag.<key>=<value>
+	Tags map[string]string
+
+	// 结构化过滤条件, 多个值之间是或的关系, 不同条件之间是与的关系
+	Vendor    []Vendor
+	Region    []string
+	Zone      []string
+	Status    []string
+	Category  []string
+	Type      []string
+	OSType    []string
+	PayType   []string
+	PublicIP  []string
+	PrivateIP []string
+	// 同步的账号
+	SyncAccount []string
+	// 范围过滤, 0表示不限制
+	CPUMin    int
+	CPUMax    int
+	MemoryMin int
+	MemoryMax int
+
+	// 排序字段, 为空时按创建时间倒序
+	Sort []*SortBy
+
+	// 游标分页模式, 基于(create_at, id)倒序翻页, 忽略PageNumber
+	UseCursor bool
+	// 上一页返回的next_cursor, 为空表示第一页
+	Cursor *Cursor
+	// 是否统计总数, 大数据量时关闭可以省掉一次COUNT查询
+	WithTotal bool
+
+	// 为true时只查询回收站中已删除的主机
+	Deleted bool
+
+	// 按命名空间过滤
+	Namespace []string
+	// 调用方可以访问的命名空间, nil表示不限制, 空列表表示没有任何命名空间的权限
+	AllowedNamespaces []string
+
+	// 只查询在该时间之后DescribeHash发生过变化的主机, 13位时间戳, 0表示不限制
+	DescribeChangedSince int64
+	// 只查询在该时间之前(含)过期的主机, 包括已经过期的, 13位时间戳, 0表示不限制
+	ExpireBefore int64
+}
+
+// 校验查询参数是否合法
+func (req *QueryHostRequest) Validate() error {
+	if req.PageSize <= 0 {
+		return fmt.Errorf("page_size must be positive")
+	}
+	if !req.UseCursor && req.PageNumber <= 0 {
+		return fmt.Errorf("page_number must be positive")
+	}
+	if req.CPUMin < 0 || req.CPUMax < 0 || req.MemoryMin < 0 || req.MemoryMax < 0 {
+		return fmt.Errorf("cpu and memory range must not be negative")
+	}
+	if req.CPUMax > 0 && req.CPUMin > req.CPUMax {
+		return fmt.Errorf("cpu_min %d greater than cpu_max %d", req.CPUMin, req.CPUMax)
+	}
+	if req.MemoryMax > 0 && req.MemoryMin > req.MemoryMax {
+		return fmt.Errorf("memory_min %d greater than memory_max %d", req.MemoryMin, req.MemoryMax)
+	}
+	for _, sb := range req.Sort {
+		if !sortableFields[sb.Field] {
+			return fmt.Errorf("field %s is not sortable", sb.Field)
+		}
+	}
+	if req.DescribeChangedSince < 0 || req.ExpireBefore < 0 {
+		return fmt.Errorf("describe_changed_since and expire_before must not be negative")
+	}
+	if req.UseCursor && len(req.Sort) > 0 {
+		return fmt.Errorf("cursor pagination only supports the default order, sort is not allowed")
+	}
+	return nil
 }
 
 func (req *QueryHostRequest) Offset() int {
@@ -32,8 +195,31 @@ func NewDescribeHostRequestWithID(id string) *DesribeHostRequest {
 	}
 }
 
+// 按厂商和实例Id查询主机
+func NewDescribeHostRequestWithInstance(vendor Vendor, instanceId string) *DesribeHostRequest {
+	return &DesribeHostRequest{
+		Vendor:     vendor,
+		InstanceId: instanceId,
+	}
+}
+
 type DesribeHostRequest struct {
 	Id string
+	// Id为空时, 按厂商和实例Id查询
+	Vendor     Vendor
+	InstanceId string
+	// 为true时回收站中已删除的主机也可以查询到
+	WithDeleted bool
+	// 调用方可以访问的命名空间, nil表示不限制, 不在范围内的主机按不存在处理
+	AllowedNamespaces []string
+}
+
+// 用于错误信息中标识查询的主机
+func (req *DesribeHostRequest) Key() string {
+	if req.Id != "" {
+		return req.Id
+	}
+	return req.Vendor.String() + "/" + req.InstanceId
 }
 
 const (
@@ -47,6 +233,12 @@ type UpdateHostRequest struct {
 	UpdateMode
 	*Resource
 	*Describe
+	// 修改人, 记录到历史版本中
+	UpdateBy string `json:"-"`
+	// 期望的版本号(If-Match), 0表示不校验
+	IfMatch int64 `json:"-"`
+	// 调用方可以访问的命名空间, nil表示不限制, 也不能把主机移动到范围之外
+	AllowedNamespaces []string `json:"-"`
 }
 
 func NewPatchUpdateHostRequest() *UpdateHostRequest {
@@ -65,6 +257,121 @@ func NewPutUpdateHostRequest() *UpdateHostRequest {
 	}
 }
 
+func NewUpsertHostRequest() *UpsertHostRequest {
+	// 不设置默认的创建时间, 更新已有主机时保留原来的创建时间
+	return &UpsertHostRequest{
+		Host: &Host{
+			Resource: &Resource{},
+			Describe: &Describe{},
+		},
+	}
+}
+
+// 厂商和实例Id作为主机的唯一标识, 服务端的Id由系统生成, 不需要调用方传递
+type UpsertHostRequest struct {
+	*Host
+	// 修改人, 记录到历史版本中
+	UpdateBy string `json:"-"`
+	// 调用方可以访问的命名空间, nil表示不限制
+	AllowedNamespaces []string `json:"-"`
+}
+
+func (req *UpsertHostRequest) Validate() error {
+	if req.Host == nil || req.Resource == nil || req.Describe == nil {
+		return fmt.Errorf("host resource and describe required")
+	}
+	if req.InstanceId == "" {
+		return fmt.Errorf("instance_id required")
+	}
+	return nil
+}
+
+// 转换成全量更新请求
+func (req *UpsertHostRequest) UpdateRequest(id string) *UpdateHostRequest {
+	update := NewPutUpdateHostRequest()
+	update.Resource = req.Resource
+	update.Describe = req.Describe
+	update.Id = id
+	update.UpdateBy = req.UpdateBy
+	update.AllowedNamespaces = req.AllowedNamespaces
+	return update
+}
+
+type UpsertHostResponse struct {
+	// true: 新录入, false: 更新已有主机
+	Created bool `json:"created"`
+	*Host
+}
+
 type DeleteHostRequest struct {
 	Id string
+	// 删除人
+	DeleteBy string
+	// 期望的版本号(If-Match), 0表示不校验
+	IfMatch int64
+	// 调用方可以访问的命名空间, nil表示不限制
+	AllowedNamespaces []string
+}
+
+type RestoreHostRequest struct {
+	Id string
+	// 恢复人
+	RestoreBy string
+	// 调用方可以访问的命名空间, nil表示不限制
+	AllowedNamespaces []string
+}
+
+func NewPurgeHostRequest(deletedBefore int64) *PurgeHostRequest {
+	return &PurgeHostRequest{
+		DeletedBefore: deletedBefore,
+	}
+}
+
+// 清除在该时间之前删除的主机, 13位时间戳
+type PurgeHostRequest struct {
+	DeletedBefore int64
+}
+
+type PurgeHostResponse struct {
+	// 清除的主机数量
+	Count int64 `json:"count"`
+}
+
+func NewQueryHostRevisionRequest(id string) *QueryHostRevisionRequest {
+	return &QueryHostRevisionRequest{
+		Id:         id,
+		PageSize:   20,
+		PageNumber: 1,
+	}
+}
+
+type QueryHostRevisionRequest struct {
+	Id         string
+	PageSize   int
+	PageNumber int
+}
+
+func (req *QueryHostRevisionRequest) Validate() error {
+	if req.PageSize <= 0 || req.PageNumber <= 0 {
+		return fmt.Errorf("page_size and page_number must be positive")
+	}
+	return nil
+}
+
+func (req *QueryHostRevisionRequest) Offset() int {
+	return (req.PageNumber - 1) * req.PageSize
+}
+
+type DescribeHostRevisionRequest struct {
+	Id string
+	// 版本号, 0表示最新版本
+	Revision int
+}
+
+type DiffHostRevisionRequest struct {
+	Id string
+	// 起始版本, 0表示目标版本的上一个版本
+	From int
+	// 目标版本, 0表示最新版本
+	To int
 }
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+	"github.com/rs/xid"
+)
+
+func (i *impl) BatchCreateHost(ctx context.Context, req *host.BatchCreateHostRequest) (*host.BatchCreateHostResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate batch create host request error, %s", err)
+	}
+
+	// 先逐条校验, 记录每条的结果
+	results := make([]*host.BatchCreateHostResult, len(req.Items))
+	valid := []int{}
+	for idx, ins := range req.Items {
+		results[idx] = &host.BatchCreateHostResult{Index: idx}
+		if ins == nil {
+			results[idx].Error = "host is null"
+			continue
+		}
+
+		ins.Id = xid.New().String()
+		ins.Version = 1
+		if ins.Resource != nil && ins.CreateAt == 0 {
+			ins.CreateAt = ftime.Now().Timestamp()
+		}
+		if err := ins.Validate(); err != nil {
+			results[idx].Error = err.Error()
+			continue
+		}
+		if err := ins.ComputeHash(); err != nil {
+			results[idx].Error = err.Error()
+			continue
+		}
+		ins.DescribeChangedAt = ins.CreateAt
+		valid = append(valid, idx)
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	// 和MySQL的唯一约束保持一致, 厂商和实例Id在已有数据和本批次内都不能重复
+	unique := []int{}
+	seen := map[string]bool{}
+	for _, idx := range valid {
+		ins := req.Items[idx]
+		if err := i.checkInstance(ins); err != nil {
+			results[idx].Error = err.Error()
+			continue
+		}
+		if ins.InstanceId != "" {
+			key := ins.Vendor.String() + "/" + ins.InstanceId
+			if seen[key] {
+				results[idx].Error = "host " + key + " is duplicated in the batch"
+				continue
+			}
+			seen[key] = true
+		}
+		unique = append(unique, idx)
+	}
+
+	aborted := req.AllOrNothing && len(unique) < len(req.Items)
+	for _, idx := range unique {
+		if aborted {
+			results[idx].Error = "aborted, other items in the batch are invalid"
+			continue
+		}
+		ins := req.Items[idx]
+		i.hosts[ins.Id] = clone(ins)
+		i.saveRevision(host.NewRevision(ins, host.ActionCreate, ins.CreateBy))
+		results[idx].Success = true
+		results[idx].Id = ins.Id
+	}
+
+	resp := host.NewBatchCreateHostResponse()
+	for _, r := range results {
+		resp.Add(r)
+	}
+	return resp, nil
+}
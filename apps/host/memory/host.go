@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+	"github.com/rs/xid"
+)
+
+func (i *impl) CreateHost(ctx context.Context, ins *host.Host) (*host.Host, error) {
+	if ins.Resource == nil || ins.Describe == nil {
+		return nil, exception.NewBadRequest("host resource and describe required")
+	}
+
+	// Id由服务端生成, 和批量录入一样先生成再校验
+	ins.Id = xid.New().String()
+
+	// 校验数据合法性
+	if err := ins.Validate(); err != nil {
+		return nil, err
+	}
+	ins.Version = 1
+	if ins.CreateAt == 0 {
+		ins.CreateAt = ftime.Now().Timestamp()
+	}
+	if err := ins.ComputeHash(); err != nil {
+		return nil, err
+	}
+	ins.DescribeChangedAt = ins.CreateAt
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if err := i.checkInstance(ins); err != nil {
+		return nil, err
+	}
+	i.hosts[ins.Id] = clone(ins)
+	i.saveRevision(host.NewRevision(ins, host.ActionCreate, ins.CreateBy))
+
+	return ins, nil
+}
+
+func (i *impl) QueryHost(ctx context.Context, req *host.QueryHostRequest) (*host.Set, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query host request error, %s", err)
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	// 过滤出匹配关键字的主机
+	matched := []*host.Host{}
+	for _, ins := range i.hosts {
+		if !match(ins, req) {
+			continue
+		}
+		matched = append(matched, ins)
+	}
+
+	// 和MySQL实现保持一致, 默认按创建时间倒序, 有关键字时精确匹配的排在前面
+	terms := host.ParseKeywords(req.Keywords)
+	rank := len(terms) > 0 && len(req.Sort) == 0 && !req.UseCursor
+	sort.SliceStable(matched, func(m, n int) bool {
+		if rank {
+			em, en := matched[m].ExactMatch(terms), matched[n].ExactMatch(terms)
+			if em != en {
+				return em
+			}
+		}
+		return less(matched[m], matched[n], req.Sort)
+	})
+
+	set := host.NewSet()
+	if req.WithTotal {
+		set.Total = int64(len(matched))
+	}
+
+	// 游标分页: 跳过游标之前的数据, 多取一条判断是否还有下一页
+	if req.UseCursor {
+		for _, ins := range matched {
+			if req.Cursor != nil && !req.Cursor.Before(ins) {
+				continue
+			}
+			if len(set.Items) == req.PageSize {
+				set.NextCursor = host.NewCursor(set.Items[len(set.Items)-1]).Encode()
+				break
+			}
+			set.Add(clone(ins))
+		}
+		return set, nil
+	}
+
+	// 分页
+	start := req.Offset()
+	if start < 0 {
+		start = 0
+	}
+	end := start + req.PageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	for idx := start; idx < end; idx++ {
+		set.Add(clone(matched[idx]))
+	}
+
+	return set, nil
+}
+
+func (i *impl) DesribeHost(ctx context.Context, req *host.DesribeHostRequest) (*host.Host, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	ins, ok := i.find(req)
+	// 其他命名空间的主机按不存在处理
+	if !ok || (ins.DeletedAt > 0 && !req.WithDeleted) || !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return nil, exception.NewNotFound("host %s not found", req.Key())
+	}
+	return clone(ins), nil
+}
+
+func (i *impl) UpdateHost(ctx context.Context, req *host.UpdateHostRequest) (*host.Host, error) {
+	// 重新查询出来
+	ins, err := i.DesribeHost(ctx, &host.DesribeHostRequest{Id: req.Id, AllowedNamespaces: req.AllowedNamespaces})
+	if err != nil {
+		return nil, err
+	}
+
+	// 乐观锁: 客户端通过If-Match指定了期望的版本号
+	if err := host.CheckVersion(ins, req.IfMatch); err != nil {
+		return nil, err
+	}
+	updateAt, syncAt := ins.UpdateAt, ins.SyncAt
+
+	// 对象更新(PATCH/PUT)
+	switch req.UpdateMode {
+	case host.PUT:
+		ins.Update(req.Resource, req.Describe)
+	case host.PATCH:
+		err := ins.Patch(req.Resource, req.Describe)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 校验更新后的数据是否合法
+	if err := ins.Validate(); err != nil {
+		return nil, err
+	}
+	// 不能把主机移动到没有权限的命名空间
+	if !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return nil, exception.NewPermissionDeny("no permission to namespace %s", ins.Namespace)
+	}
+
+	// 内容没有变化时不写入, 避免产生无意义的版本
+	resChanged, descChanged, err := ins.Rehash()
+	if err != nil {
+		return nil, err
+	}
+	if !resChanged && !descChanged {
+		ins.UpdateAt = updateAt
+		ins.Unchanged = true
+		// 同步时间记录最近一次看到实例的时间, 内容没有变化也需要刷新, 不产生新版本
+		if ins.SyncAt <= syncAt {
+			ins.SyncAt = syncAt
+			return ins, nil
+		}
+		i.lock.Lock()
+		defer i.lock.Unlock()
+		if old, ok := i.hosts[ins.Id]; ok {
+			old.SyncAt = ins.SyncAt
+		}
+		return ins, nil
+	}
+	if descChanged {
+		ins.DescribeChangedAt = ins.UpdateAt
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if old, ok := i.hosts[ins.Id]; !ok || old.DeletedAt > 0 {
+		return nil, exception.NewNotFound("host %s not found", ins.Id)
+	} else if old.Version != ins.Version {
+		// 读取之后被其他请求修改过
+		return nil, host.NewPreconditionFailed("host %s has been modified by others, please retry", ins.Id)
+	}
+	if err := i.checkInstance(ins); err != nil {
+		return nil, err
+	}
+	ins.Version++
+	i.hosts[ins.Id] = clone(ins)
+	i.saveRevision(host.NewRevision(ins, req.UpdateMode.Action(), req.UpdateBy))
+
+	return ins, nil
+}
+
+// 软删除, 只标记删除时间和删除人, 数据保留在回收站中
+func (i *impl) DeleteHost(ctx context.Context, req *host.DeleteHostRequest) (*host.Host, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	ins, ok := i.hosts[req.Id]
+	if !ok || ins.DeletedAt > 0 || !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return nil, exception.NewNotFound("host %s not found", req.Id)
+	}
+	if err := host.CheckVersion(ins, req.IfMatch); err != nil {
+		return nil, err
+	}
+	ins.DeletedAt = ftime.Now().Timestamp()
+	ins.DeletedBy = req.DeleteBy
+	ins.Version++
+	i.saveRevision(host.NewRevision(ins, host.ActionDelete, req.DeleteBy))
+
+	return clone(ins), nil
+}
+
+// 从回收站中恢复主机
+func (i *impl) RestoreHost(ctx context.Context, req *host.RestoreHostRequest) (*host.Host, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	ins, ok := i.hosts[req.Id]
+	if !ok || !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return nil, exception.NewNotFound("host %s not found", req.Id)
+	}
+	if ins.DeletedAt == 0 {
+		return nil, exception.NewBadRequest("host %s is not deleted", req.Id)
+	}
+	ins.DeletedAt = 0
+	ins.DeletedBy = ""
+	ins.Version++
+	i.saveRevision(host.NewRevision(ins, host.ActionRestore, req.RestoreBy))
+
+	return clone(ins), nil
+}
+
+// 彻底清除回收站中超过保留期的主机
+func (i *impl) PurgeHost(ctx context.Context, req *host.PurgeHostRequest) (*host.PurgeHostResponse, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	resp := &host.PurgeHostResponse{}
+	for id, ins := range i.hosts {
+		if ins.DeletedAt > 0 && ins.DeletedAt < req.DeletedBefore {
+			delete(i.hosts, id)
+			delete(i.revisions, id)
+			resp.Count++
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,390 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHost(name string) *host.Host {
+	ins := host.NewDefaultHost()
+	ins.Namespace = host.DefaultNamespace
+	ins.Region = "hangzhou"
+	ins.Type = "sm1"
+	ins.Name = name
+	ins.CPU = 1
+	ins.Memory = 2048
+	return ins
+}
+
+func TestHostCRUD(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	created := newTestHost("host01")
+	created.CreateBy = "admin"
+	ins, err := memory.Service.CreateHost(ctx, created)
+	if !should.NoError(err) {
+		return
+	}
+	revs, err := memory.Service.QueryHostRevision(ctx, host.NewQueryHostRevisionRequest(ins.Id))
+	if should.NoError(err) && should.Len(revs.Items, 1) {
+		should.Equal("admin", revs.Items[0].Operator)
+	}
+	badPage := host.NewQueryHostRevisionRequest(ins.Id)
+	badPage.PageNumber = 0
+	_, err = memory.Service.QueryHostRevision(ctx, badPage)
+	if should.Error(err) {
+		should.Equal(exception.BadRequest, err.(exception.APIException).ErrorCode())
+	}
+	other := newTestHost("host02")
+	other.Tags = map[string]string{"env": "prod"}
+	_, err = memory.Service.CreateHost(ctx, other)
+	should.NoError(err)
+
+	set, err := memory.Service.QueryHost(ctx, &host.QueryHostRequest{PageSize: 20, PageNumber: 1, Tags: map[string]string{"env": "prod"}})
+	if should.NoError(err) && should.Len(set.Items, 1) {
+		should.Equal("host02", set.Items[0].Name)
+	}
+
+	set, err = memory.Service.QueryHost(ctx, &host.QueryHostRequest{PageSize: 1, PageNumber: 1, Keywords: "host", WithTotal: true})
+	if should.NoError(err) {
+		should.Equal(int64(2), set.Total)
+		should.Len(set.Items, 1)
+	}
+
+	sorted, err := memory.Service.QueryHost(ctx, &host.QueryHostRequest{PageSize: 20, PageNumber: 1, Sort: []*host.SortBy{{Field: "name", Desc: true}}})
+	if should.NoError(err) && should.Len(sorted.Items, 2) {
+		should.Equal("host02", sorted.Items[0].Name)
+	}
+
+	// 游标分页, 每页一条, 翻完两页后没有下一页
+	req := &host.QueryHostRequest{PageSize: 1, UseCursor: true}
+	first, err := memory.Service.QueryHost(ctx, req)
+	if should.NoError(err) && should.Len(first.Items, 1) && should.NotEmpty(first.NextCursor) {
+		req.Cursor, err = host.ParseCursor(first.NextCursor)
+		should.NoError(err)
+		second, err := memory.Service.QueryHost(ctx, req)
+		if should.NoError(err) && should.Len(second.Items, 1) {
+			should.NotEqual(first.Items[0].Id, second.Items[0].Id)
+			should.Empty(second.NextCursor)
+		}
+	}
+
+	patch := host.NewPatchUpdateHostRequest()
+	patch.Id = ins.Id
+	patch.Name = "patched"
+	patch.Tags = map[string]string{"owner": "ops"}
+	updated, err := memory.Service.UpdateHost(ctx, patch)
+	if should.NoError(err) {
+		should.Equal("patched", updated.Name)
+		should.Equal(2048, updated.Memory)
+		should.Equal("ops", updated.Tags["owner"])
+	}
+
+	desc, err := memory.Service.DesribeHost(ctx, host.NewDescribeHostRequestWithID(ins.Id))
+	if should.NoError(err) {
+		should.Equal("patched", desc.Name)
+	}
+
+	_, err = memory.Service.DeleteHost(ctx, &host.DeleteHostRequest{Id: ins.Id})
+	should.NoError(err)
+	_, err = memory.Service.DesribeHost(ctx, host.NewDescribeHostRequestWithID(ins.Id))
+	should.True(exception.IsNotFoundError(err))
+}
+
+func TestBatchCreateHost(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	invalid := newTestHost("")
+	req := host.NewBatchCreateHostRequest()
+	req.Items = append(req.Items, newTestHost("batch01"), invalid)
+	req.AllOrNothing = true
+
+	resp, err := memory.Service.BatchCreateHost(ctx, req)
+	if should.NoError(err) {
+		should.Equal(0, resp.SuccessCount)
+		should.Equal(2, resp.FailedCount)
+	}
+
+	req.AllOrNothing = false
+	resp, err = memory.Service.BatchCreateHost(ctx, req)
+	if should.NoError(err) && should.Len(resp.Items, 2) {
+		should.True(resp.Items[0].Success)
+		should.NotEmpty(resp.Items[0].Id)
+		should.False(resp.Items[1].Success)
+		should.NotEmpty(resp.Items[1].Error)
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	ins, err := memory.Service.CreateHost(ctx, newTestHost("recycle01"))
+	if !should.NoError(err) {
+		return
+	}
+
+	deleted, err := memory.Service.DeleteHost(ctx, &host.DeleteHostRequest{Id: ins.Id, DeleteBy: "admin"})
+	if should.NoError(err) {
+		should.NotZero(deleted.DeletedAt)
+		should.Equal("admin", deleted.DeletedBy)
+	}
+
+	req := host.NewQueryHostRequest()
+	set, err := memory.Service.QueryHost(ctx, req)
+	if should.NoError(err) {
+		should.Empty(set.Items)
+	}
+	req.Deleted = true
+	set, err = memory.Service.QueryHost(ctx, req)
+	if should.NoError(err) {
+		should.Len(set.Items, 1)
+	}
+
+	restored, err := memory.Service.RestoreHost(ctx, &host.RestoreHostRequest{Id: ins.Id, RestoreBy: "ops"})
+	if should.NoError(err) {
+		should.Zero(restored.DeletedAt)
+	}
+
+	// 删除和恢复都会产生新版本
+	revs, err := memory.Service.QueryHostRevision(ctx, host.NewQueryHostRevisionRequest(ins.Id))
+	if should.NoError(err) && should.Len(revs.Items, 3) {
+		should.Equal(host.ActionRestore, revs.Items[0].Action)
+		should.Equal("ops", revs.Items[0].Operator)
+		should.Equal(host.ActionDelete, revs.Items[1].Action)
+		should.Equal("admin", revs.Items[1].Operator)
+	}
+
+	_, err = memory.Service.DeleteHost(ctx, &host.DeleteHostRequest{Id: ins.Id})
+	should.NoError(err)
+	resp, err := memory.Service.PurgeHost(ctx, host.NewPurgeHostRequest(deleted.DeletedAt+60*1000))
+	if should.NoError(err) {
+		should.Equal(int64(1), resp.Count)
+	}
+	_, err = memory.Service.RestoreHost(ctx, &host.RestoreHostRequest{Id: ins.Id})
+	should.True(exception.IsNotFoundError(err))
+	revs, err = memory.Service.QueryHostRevision(ctx, host.NewQueryHostRevisionRequest(ins.Id))
+	if should.NoError(err) {
+		should.Zero(revs.Total)
+	}
+}
+
+func TestUpdateHostIfMatch(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	ins, err := memory.Service.CreateHost(ctx, newTestHost("etag01"))
+	if !should.NoError(err) {
+		return
+	}
+	should.Equal(`"1"`, ins.ETag())
+
+	patch := host.NewPatchUpdateHostRequest()
+	patch.Id = ins.Id
+	patch.Name = "etag02"
+	patch.IfMatch = 1
+	updated, err := memory.Service.UpdateHost(ctx, patch)
+	if should.NoError(err) {
+		should.Equal(int64(2), updated.Version)
+	}
+
+	// 使用过期的版本号修改, 返回412
+	_, err = memory.Service.UpdateHost(ctx, patch)
+	if e, ok := err.(exception.APIException); should.True(ok) {
+		should.Equal(http.StatusPreconditionFailed, e.ErrorCode())
+	}
+	_, err = memory.Service.DeleteHost(ctx, &host.DeleteHostRequest{Id: ins.Id, IfMatch: 1})
+	should.Error(err)
+}
+
+func TestUpdateHostUnchanged(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	ins, err := memory.Service.CreateHost(ctx, newTestHost("host01"))
+	if !should.NoError(err) {
+		return
+	}
+	should.NotEmpty(ins.ResourceHash)
+	should.NotEmpty(ins.DescribeHash)
+
+	// 内容没有变化, 不产生新版本
+	patch := host.NewPatchUpdateHostRequest()
+	patch.Id = ins.Id
+	patch.Name = "host01"
+	updated, err := memory.Service.UpdateHost(ctx, patch)
+	if should.NoError(err) {
+		should.True(updated.Unchanged)
+		should.Equal(int64(1), updated.Version)
+	}
+
+	// 只修改Describe, DescribeChangedAt 随之更新
+	patch = host.NewPatchUpdateHostRequest()
+	patch.Id = ins.Id
+	patch.CPU = 4
+	updated, err = memory.Service.UpdateHost(ctx, patch)
+	if should.NoError(err) {
+		should.False(updated.Unchanged)
+		should.Equal(int64(2), updated.Version)
+		should.Equal(ins.ResourceHash, updated.ResourceHash)
+		should.NotEqual(ins.DescribeHash, updated.DescribeHash)
+		should.Equal(updated.UpdateAt, updated.DescribeChangedAt)
+	}
+
+	set, err := memory.Service.QueryHost(ctx, &host.QueryHostRequest{PageSize: 20, PageNumber: 1, DescribeChangedSince: updated.DescribeChangedAt})
+	if should.NoError(err) {
+		should.Len(set.Items, 1)
+	}
+}
+
+func TestUpsertHost(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	req := host.NewUpsertHostRequest()
+	req.Host = newTestHost("host01")
+	req.Id = ""
+	req.Vendor = host.TX_CLOUD
+	req.InstanceId = "ins-01"
+	created, err := memory.Service.UpsertHost(ctx, req)
+	if !should.NoError(err) {
+		return
+	}
+	should.True(created.Created)
+
+	// 再次录入同一个实例, 更新已有主机
+	req = host.NewUpsertHostRequest()
+	req.Host = newTestHost("host01-renamed")
+	req.CreateAt = 0
+	req.Vendor = host.TX_CLOUD
+	req.InstanceId = "ins-01"
+	updated, err := memory.Service.UpsertHost(ctx, req)
+	if should.NoError(err) {
+		should.False(updated.Created)
+		should.Equal(created.Id, updated.Id)
+		should.Equal("host01-renamed", updated.Name)
+		should.Equal(created.CreateAt, updated.CreateAt)
+	}
+
+	// 厂商和实例Id唯一
+	dup := newTestHost("host02")
+	dup.Vendor = host.TX_CLOUD
+	dup.InstanceId = "ins-01"
+	_, err = memory.Service.CreateHost(ctx, dup)
+	should.True(exception.IsConflictError(err))
+}
+
+func TestNamespaceScope(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	other := newTestHost("team-b-host")
+	other.Namespace = "team-b"
+	ins, err := memory.Service.CreateHost(ctx, other)
+	if !should.NoError(err) {
+		return
+	}
+	_, err = memory.Service.CreateHost(ctx, newTestHost("default-host"))
+	should.NoError(err)
+
+	allowed := []string{host.DefaultNamespace}
+	req := host.NewQueryHostRequest()
+	req.AllowedNamespaces = allowed
+	set, err := memory.Service.QueryHost(ctx, req)
+	if should.NoError(err) && should.Len(set.Items, 1) {
+		should.Equal("default-host", set.Items[0].Name)
+	}
+
+	// 没有任何命名空间的权限
+	req.AllowedNamespaces = []string{}
+	set, err = memory.Service.QueryHost(ctx, req)
+	if should.NoError(err) {
+		should.Empty(set.Items)
+	}
+
+	// 其他命名空间的主机按不存在处理
+	_, err = memory.Service.DesribeHost(ctx, &host.DesribeHostRequest{Id: ins.Id, AllowedNamespaces: allowed})
+	should.True(exception.IsNotFoundError(err))
+	_, err = memory.Service.DeleteHost(ctx, &host.DeleteHostRequest{Id: ins.Id, AllowedNamespaces: allowed})
+	should.True(exception.IsNotFoundError(err))
+
+	patch := host.NewPatchUpdateHostRequest()
+	patch.Id = ins.Id
+	patch.Name = "renamed"
+	patch.AllowedNamespaces = allowed
+	_, err = memory.Service.UpdateHost(ctx, patch)
+	should.True(exception.IsNotFoundError(err))
+}
+
+func TestStatsHost(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	for idx, region := range []string{"hangzhou", "hangzhou", "shanghai"} {
+		ins := newTestHost(fmt.Sprintf("host%02d", idx))
+		ins.Region = region
+		ins.CPU = idx + 1
+		_, err := memory.Service.CreateHost(ctx, ins)
+		should.NoError(err)
+	}
+
+	req := host.NewStatsHostRequest()
+	req.GroupBy = []string{"region"}
+	set, err := memory.Service.StatsHost(ctx, req)
+	if should.NoError(err) && should.Len(set.Items, 2) {
+		should.Equal("hangzhou", set.Items[0].Group["region"])
+		should.Equal(int64(2), set.Items[0].Count)
+		should.Equal(int64(3), set.Items[0].CPU)
+		should.Equal(int64(3), set.Total.Count)
+		should.Equal(int64(3*2048), set.Total.Memory)
+	}
+
+	req.GroupBy = []string{"name"}
+	_, err = memory.Service.StatsHost(ctx, req)
+	if should.Error(err) {
+		e, ok := err.(exception.APIException)
+		should.True(ok && e.ErrorCode() == exception.BadRequest)
+	}
+}
+
+func TestSearchHost(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+	should.NoError(memory.Service.Init())
+
+	for _, name := range []string{"web01-backup", "web01", "db01"} {
+		ins := newTestHost(name)
+		ins.PrivateIP = "10.0.0." + name[len(name)-1:]
+		_, err := memory.Service.CreateHost(ctx, ins)
+		should.NoError(err)
+	}
+
+	// 精确匹配的排在前面
+	set, err := memory.Service.QueryHost(ctx, &host.QueryHostRequest{PageSize: 20, PageNumber: 1, Keywords: "WEB01"})
+	if should.NoError(err) && should.Len(set.Items, 2) {
+		should.Equal("web01", set.Items[0].Name)
+	}
+
+	set, err = memory.Service.QueryHost(ctx, &host.QueryHostRequest{PageSize: 20, PageNumber: 1, Keywords: `ip:10.0.0. db`})
+	if should.NoError(err) && should.Len(set.Items, 1) {
+		should.Equal("db01", set.Items[0].Name)
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"sync"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// 基于内存的host.Service实现, 不依赖数据库, 用于本地开发和测试
+var Service *impl = &impl{}
+
+type impl struct {
+	log logger.Logger
+
+	// 保护hosts的并发读写
+	lock sync.RWMutex
+	// 主机数据, key 为主机Id
+	hosts map[string]*host.Host
+	// 主机的历史版本, 按版本号升序
+	revisions map[string][]*host.Revision
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("Host")
+	i.hosts = map[string]*host.Host{}
+	i.revisions = map[string][]*host.Revision{}
+	return nil
+}
+
+// 深拷贝一个主机对象, 避免调用方修改到内存中保存的数据
+func clone(ins *host.Host) *host.Host {
+	res := *ins.Resource
+	desc := *ins.Describe
+	if ins.Tags != nil {
+		res.Tags = make(map[string]string, len(ins.Tags))
+		for k, v := range ins.Tags {
+			res.Tags[k] = v
+		}
+	}
+
+	return &host.Host{
+		ResourceHash:      ins.ResourceHash,
+		DescribeHash:      ins.DescribeHash,
+		DescribeChangedAt: ins.DescribeChangedAt,
+		Version:           ins.Version,
+		Resource:          &res,
+		Describe:          &desc,
+	}
+}
+
+// 厂商和实例Id唯一, 实例Id为空时不校验, 调用方需要持有锁
+func (i *impl) checkInstance(ins *host.Host) error {
+	if ins.InstanceId == "" {
+		return nil
+	}
+	for id, h := range i.hosts {
+		if id != ins.Id && h.Vendor == ins.Vendor && h.InstanceId == ins.InstanceId {
+			return exception.NewConflict("host %s/%s already exists", ins.Vendor, ins.InstanceId)
+		}
+	}
+	return nil
+}
+
+// 按Id或者厂商和实例Id查找主机, 调用方需要持有锁
+func (i *impl) find(req *host.DesribeHostRequest) (*host.Host, bool) {
+	if req.Id != "" {
+		ins, ok := i.hosts[req.Id]
+		return ins, ok
+	}
+	for _, ins := range i.hosts {
+		if ins.Vendor == req.Vendor && ins.InstanceId == req.InstanceId {
+			return ins, true
+		}
+	}
+	return nil, false
+}
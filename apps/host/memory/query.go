@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 判断主机是否满足查询条件, 语义和MySQL实现的Where条件保持一致
+func match(ins *host.Host, req *host.QueryHostRequest) bool {
+	// 默认不包含已删除的主机, deleted=true 时只查询回收站
+	if req.Deleted != (ins.DeletedAt > 0) {
+		return false
+	}
+	if terms := host.ParseKeywords(req.Keywords); len(terms) > 0 && !ins.MatchSearch(terms) {
+		return false
+	}
+	if !matchTags(ins, req.Tags) {
+		return false
+	}
+
+	if len(req.Vendor) > 0 {
+		found := false
+		for _, v := range req.Vendor {
+			if v == ins.Vendor {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !in(ins.Region, req.Region) || !in(ins.Zone, req.Zone) || !in(ins.Status, req.Status) ||
+		!in(ins.Category, req.Category) || !in(ins.Type, req.Type) || !in(ins.PayType, req.PayType) ||
+		!in(ins.PublicIP, req.PublicIP) || !in(ins.PrivateIP, req.PrivateIP) || !in(ins.OSType, req.OSType) ||
+		!in(ins.SyncAccount, req.SyncAccount) || !in(ins.Namespace, req.Namespace) {
+		return false
+	}
+	if !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return false
+	}
+
+	if req.CPUMin > 0 && ins.CPU < req.CPUMin {
+		return false
+	}
+	if req.CPUMax > 0 && ins.CPU > req.CPUMax {
+		return false
+	}
+	if req.MemoryMin > 0 && ins.Memory < req.MemoryMin {
+		return false
+	}
+	if req.MemoryMax > 0 && ins.Memory > req.MemoryMax {
+		return false
+	}
+	if req.DescribeChangedSince > 0 && ins.DescribeChangedAt < req.DescribeChangedSince {
+		return false
+	}
+	if req.ExpireBefore > 0 && (ins.ExpireAt == 0 || ins.ExpireAt > req.ExpireBefore) {
+		return false
+	}
+	return true
+}
+
+// 主机需要包含所有的过滤标签
+func matchTags(ins *host.Host, tags map[string]string) bool {
+	for k, v := range tags {
+		if tv, ok := ins.Tags[k]; !ok || tv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// 过滤列表为空时不限制
+func in(value string, list []string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
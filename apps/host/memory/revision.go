@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 保存一个新版本, 调用方需要持有写锁
+func (i *impl) saveRevision(rev *host.Revision) {
+	rev.Host = clone(rev.Host)
+	rev.Revision = len(i.revisions[rev.HostId]) + 1
+	i.revisions[rev.HostId] = append(i.revisions[rev.HostId], rev)
+}
+
+func (i *impl) QueryHostRevision(ctx context.Context, req *host.QueryHostRevisionRequest) (*host.RevisionSet, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query host revision request error, %s", err)
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	revisions := i.revisions[req.Id]
+	set := host.NewRevisionSet()
+	set.Total = int64(len(revisions))
+
+	// 按版本号倒序分页
+	for idx := len(revisions) - 1 - req.Offset(); idx >= 0 && len(set.Items) < req.PageSize; idx-- {
+		set.Add(cloneRevision(revisions[idx]))
+	}
+	return set, nil
+}
+
+func (i *impl) DescribeHostRevision(ctx context.Context, req *host.DescribeHostRevisionRequest) (*host.Revision, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	revisions := i.revisions[req.Id]
+	idx := req.Revision - 1
+	if req.Revision == 0 {
+		idx = len(revisions) - 1
+	}
+	if idx < 0 || idx >= len(revisions) {
+		return nil, exception.NewNotFound("host %s revision %d not found", req.Id, req.Revision)
+	}
+	return cloneRevision(revisions[idx]), nil
+}
+
+func (i *impl) DiffHostRevision(ctx context.Context, req *host.DiffHostRevisionRequest) (*host.RevisionDiff, error) {
+	to, err := i.DescribeHostRevision(ctx, &host.DescribeHostRevisionRequest{Id: req.Id, Revision: req.To})
+	if err != nil {
+		return nil, err
+	}
+
+	fromRevision := req.From
+	if fromRevision == 0 {
+		fromRevision = to.Revision - 1
+	}
+	if fromRevision < 1 {
+		return nil, exception.NewBadRequest("host %s revision %d has no previous revision", req.Id, to.Revision)
+	}
+
+	from, err := i.DescribeHostRevision(ctx, &host.DescribeHostRevisionRequest{Id: req.Id, Revision: fromRevision})
+	if err != nil {
+		return nil, err
+	}
+	return host.NewRevisionDiff(from, to)
+}
+
+func cloneRevision(rev *host.Revision) *host.Revision {
+	c := *rev
+	c.Host = clone(rev.Host)
+	return &c
+}
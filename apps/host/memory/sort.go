@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+var (
+	// 排序字段对应的取值方法, 和MySQL实现的排序字段保持一致
+	sortValues = map[string]func(*host.Host) interface{}{
+		"namespace":                  func(h *host.Host) interface{} { return h.Namespace },
+		"vendor":                     func(h *host.Host) interface{} { return int64(h.Vendor) },
+		"region":                     func(h *host.Host) interface{} { return h.Region },
+		"zone":                       func(h *host.Host) interface{} { return h.Zone },
+		"create_at":                  func(h *host.Host) interface{} { return h.CreateAt },
+		"expire_at":                  func(h *host.Host) interface{} { return h.ExpireAt },
+		"update_at":                  func(h *host.Host) interface{} { return h.UpdateAt },
+		"sync_at":                    func(h *host.Host) interface{} { return h.SyncAt },
+		"category":                   func(h *host.Host) interface{} { return h.Category },
+		"type":                       func(h *host.Host) interface{} { return h.Type },
+		"instance_id":                func(h *host.Host) interface{} { return h.InstanceId },
+		"name":                       func(h *host.Host) interface{} { return h.Name },
+		"status":                     func(h *host.Host) interface{} { return h.Status },
+		"public_ip":                  func(h *host.Host) interface{} { return h.PublicIP },
+		"private_ip":                 func(h *host.Host) interface{} { return h.PrivateIP },
+		"pay_type":                   func(h *host.Host) interface{} { return h.PayType },
+		"cpu":                        func(h *host.Host) interface{} { return int64(h.CPU) },
+		"memory":                     func(h *host.Host) interface{} { return int64(h.Memory) },
+		"gpu_amount":                 func(h *host.Host) interface{} { return int64(h.GPUAmount) },
+		"os_type":                    func(h *host.Host) interface{} { return h.OSType },
+		"os_name":                    func(h *host.Host) interface{} { return h.OSName },
+		"internet_max_bandwidth_out": func(h *host.Host) interface{} { return int64(h.InternetMaxBandwidthOut) },
+		"internet_max_bandwidth_in":  func(h *host.Host) interface{} { return int64(h.InternetMaxBandwidthIn) },
+	}
+)
+
+// 按排序字段比较两个主机, 为空时按创建时间倒序
+func less(a, b *host.Host, sorts []*host.SortBy) bool {
+	if len(sorts) == 0 {
+		if a.CreateAt == b.CreateAt {
+			return a.Id > b.Id
+		}
+		return a.CreateAt > b.CreateAt
+	}
+
+	for _, sb := range sorts {
+		value, ok := sortValues[sb.Field]
+		if !ok {
+			continue
+		}
+		c := compare(value(a), value(b))
+		if c == 0 {
+			continue
+		}
+		if sb.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	// 排序字段的值相同时按主键排序, 保证分页结果稳定
+	return a.Id < b.Id
+}
+
+func compare(a, b interface{}) int {
+	switch av := a.(type) {
+	case int64:
+		bv := b.(int64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(av, b.(string))
+	}
+	return 0
+}
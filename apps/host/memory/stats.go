@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+func (i *impl) StatsHost(ctx context.Context, req *host.StatsHostRequest) (*host.StatsSet, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate stats host request error, %s", err)
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	// 分组字段的值拼接成key
+	groups := map[string]*host.HostStats{}
+	order := []string{}
+	for _, ins := range i.hosts {
+		if !match(ins, req.QueryHostRequest) {
+			continue
+		}
+
+		values := make([]string, 0, len(req.GroupBy))
+		for _, f := range req.GroupBy {
+			values = append(values, ins.GroupValue(f))
+		}
+		key := strings.Join(values, "\x00")
+
+		item, ok := groups[key]
+		if !ok {
+			item = &host.HostStats{}
+			if len(req.GroupBy) > 0 {
+				item.Group = map[string]string{}
+				for idx, f := range req.GroupBy {
+					item.Group[f] = values[idx]
+				}
+			}
+			groups[key] = item
+			order = append(order, key)
+		}
+		item.Add(ins)
+	}
+
+	set := host.NewStatsSet(req.GroupBy)
+	// 和MySQL保持一致, 不分组时没有数据也返回一行0值
+	if len(req.GroupBy) == 0 && len(order) == 0 {
+		set.Add(&host.HostStats{})
+	}
+	for _, key := range order {
+		set.Add(groups[key])
+	}
+	set.Sort()
+	return set, nil
+}
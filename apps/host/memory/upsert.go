@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+func (i *impl) UpsertHost(ctx context.Context, req *host.UpsertHostRequest) (*host.UpsertHostResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate upsert host request error, %s", err)
+	}
+
+	// 并发录入同一个实例时, 只有一个请求能录入成功, 其他请求重试一次走更新逻辑
+	resp, err := i.upsert(ctx, req)
+	if exception.IsConflictError(err) {
+		resp, err = i.upsert(ctx, req)
+	}
+	return resp, err
+}
+
+func (i *impl) upsert(ctx context.Context, req *host.UpsertHostRequest) (*host.UpsertHostResponse, error) {
+	describe := host.NewDescribeHostRequestWithInstance(req.Vendor, req.InstanceId)
+	describe.WithDeleted = true
+	ins, err := i.DesribeHost(ctx, describe)
+	if exception.IsNotFoundError(err) {
+		if !host.NamespaceAllowed(req.AllowedNamespaces, req.Namespace) {
+			return nil, exception.NewPermissionDeny("no permission to namespace %s", req.Namespace)
+		}
+		req.CreateBy = req.UpdateBy
+		ins, err = i.CreateHost(ctx, req.Host)
+		if err != nil {
+			return nil, err
+		}
+		return &host.UpsertHostResponse{Created: true, Host: ins}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 实例已经录入到其他命名空间, 不暴露所在的命名空间
+	if !host.NamespaceAllowed(req.AllowedNamespaces, ins.Namespace) {
+		return nil, exception.NewConflict("host %s already exists", describe.Key())
+	}
+	if ins.DeletedAt > 0 {
+		return nil, exception.NewConflict("host %s is in the recycle bin, restore it first", describe.Key())
+	}
+
+	ins, err = i.UpdateHost(ctx, req.UpdateRequest(ins.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &host.UpsertHostResponse{Host: ins}, nil
+}
@@ -1,6 +1,9 @@
 package host
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -13,6 +16,7 @@ var (
 
 func NewDefaultHost() *Host {
 	return &Host{
+		Version: 1,
 		Resource: &Resource{
 			CreateAt: time.Now().UnixNano() / 1000000,
 		},
@@ -22,21 +26,42 @@ func NewDefaultHost() *Host {
 
 // 为了后期做资源解索， <ip> --> host, eip, slb, redis, mysql
 type Host struct {
+	// 由服务端根据Resource和Describe计算, 客户端传递的值会被忽略
 	ResourceHash string `json:"resource_hash"`
 	DescribeHash string `json:"describe_hash"`
+	// DescribeHash 最近一次变化的时间
+	DescribeChangedAt int64 `json:"describe_changed_at"`
+	// 乐观锁版本号, 每次修改加1, 对外通过ETag暴露
+	Version int64 `json:"version"`
+	// 只在修改的响应中使用, 不入库: 修改后内容没有变化, 没有写入
+	Unchanged bool `json:"unchanged,omitempty"`
+	// 创建人, 不入库, 只记录到第一个历史版本中
+	CreateBy string `json:"-"`
 	*Resource
 	*Describe
 }
 
 func (h *Host) Validate() error {
+	if h.Resource == nil || h.Describe == nil {
+		return fmt.Errorf("host resource and describe required")
+	}
 	return validate.Struct(h)
 }
 
+// 校验除Id以外的字段, 用于Id还没有由服务端生成时的预检查
+func (h *Host) ValidateWithoutId() error {
+	if h.Resource == nil || h.Describe == nil {
+		return fmt.Errorf("host resource and describe required")
+	}
+	return validate.StructExcept(h, "Resource.Id")
+}
+
 func (h *Host) Patch(res *Resource, desc *Describe) error {
 	h.UpdateAt = time.Now().UnixNano() / 1000000
 
 	//
 	if res != nil {
+		res.DeletedAt, res.DeletedBy = h.DeletedAt, h.DeletedBy
 		err := mergo.MergeWithOverwrite(h.Resource, res)
 		if err != nil {
 			return err
@@ -54,9 +79,15 @@ func (h *Host) Patch(res *Resource, desc *Describe) error {
 
 // go 1.17 允许获取毫秒了
 func (h *Host) Update(res *Resource, desc *Describe) {
-	h.UpdateAt = time.Now().UnixNano() / 1000000
+	// 创建时间不允许通过全量更新修改
+	if res.CreateAt == 0 {
+		res.CreateAt = h.CreateAt
+	}
+	// 软删除信息只能通过删除和恢复接口修改
+	res.DeletedAt, res.DeletedBy = h.DeletedAt, h.DeletedBy
 	h.Resource = res
 	h.Describe = desc
+	h.UpdateAt = time.Now().UnixNano() / 1000000
 }
 
 type Vendor int
@@ -67,12 +98,45 @@ const (
 	HW_CLOUD
 )
 
+var (
+	vendorNames = map[Vendor]string{
+		ALI_CLOUD: "ALI_CLOUD",
+		TX_CLOUD:  "TX_CLOUD",
+		HW_CLOUD:  "HW_CLOUD",
+	}
+)
+
+func (v Vendor) String() string {
+	if name, ok := vendorNames[v]; ok {
+		return name
+	}
+	return strconv.Itoa(int(v))
+}
+
+// 解析厂商, 支持名称(ALI_CLOUD)和数字(0)两种格式
+func ParseVendor(s string) (Vendor, error) {
+	for v, name := range vendorNames {
+		if strings.EqualFold(name, s) {
+			return v, nil
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err == nil {
+		if _, ok := vendorNames[Vendor(n)]; ok {
+			return Vendor(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown vendor %s", s)
+}
+
 // 主机的元数据信息, Region 创建时间
 type Resource struct {
-	Id     string `json:"id"  validate:"required"`     // 全局唯一Id
-	Vendor Vendor `json:"vendor"`                      // 厂商
-	Region string `json:"region"  validate:"required"` // 地域
-	Zone   string `json:"zone"`                        // 区域
+	Id        string `json:"id"  validate:"required"`       // 全局唯一Id
+	Namespace string `json:"namespace" validate:"required"` // 所属的命名空间(团队/项目)
+	Vendor    Vendor `json:"vendor"`                        // 厂商
+	Region    string `json:"region"  validate:"required"`   // 地域
+	Zone      string `json:"zone"`                          // 区域
 	// 使用13位的时间戳
 	// 为什么不只用Datetime，如果使用数据库的时间, 数据库会给你默认加上时区
 	CreateAt    int64             `json:"create_at"`                 // 创建时间
@@ -91,6 +155,9 @@ type Resource struct {
 	PublicIP  string `json:"public_ip"`  // 公网IP
 	PrivateIP string `json:"private_ip"` // 内网IP
 	PayType   string `json:"pay_type"`   // 实例付费方式
+	// 软删除信息, 未删除时为空
+	DeletedAt int64  `json:"deleted_at,omitempty"` // 删除时间
+	DeletedBy string `json:"deleted_by,omitempty"` // 删除人
 }
 
 // 主机的具体信息
@@ -112,8 +179,11 @@ type Describe struct {
 
 // 分页查询响应数据
 type Set struct {
+	// 不统计总数时为0
 	Total int64   `json:"total"`
 	Items []*Host `json:"items"`
+	// 游标分页模式下的下一页游标, 为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func (s *Set) Add(item *Host) {
@@ -20,3 +20,57 @@ func TestHostUpdate(t *testing.T) {
 		should.Equal(patch.Name, h.Name)
 	}
 }
+
+func TestHostDiff(t *testing.T) {
+	should := assert.New(t)
+
+	from := host.NewDefaultHost()
+	from.PrivateIP = "10.0.0.1"
+	from.Tags = map[string]string{"env": "dev", "owner": "ops"}
+	to := host.NewDefaultHost()
+	to.CreateAt = from.CreateAt
+	to.PrivateIP = "10.0.0.2"
+	to.Tags = map[string]string{"env": "prod", "owner": "ops"}
+
+	changes, err := host.Diff(from, to)
+	if should.NoError(err) && should.Len(changes, 2) {
+		should.Equal("private_ip", changes[0].Field)
+		should.Equal("10.0.0.1", changes[0].From)
+		should.Equal("10.0.0.2", changes[0].To)
+		should.Equal("tags.env", changes[1].Field)
+	}
+}
+
+func TestValidateWithoutId(t *testing.T) {
+	should := assert.New(t)
+
+	h := host.NewDefaultHost()
+	h.Namespace = host.DefaultNamespace
+	h.Region = "hangzhou"
+	h.Type = "sm1"
+	h.Name = "host01"
+	h.CPU = 1
+	h.Memory = 2048
+	should.Error(h.Validate())
+	should.NoError(h.ValidateWithoutId())
+
+	h.Name = ""
+	should.Error(h.ValidateWithoutId())
+}
+
+func TestHostUpdateKeepDeleted(t *testing.T) {
+	should := assert.New(t)
+
+	h := host.NewDefaultHost()
+	put := host.NewDefaultHost()
+	put.DeletedAt = 1
+	put.DeletedBy = "someone"
+	h.Update(put.Resource, put.Describe)
+	should.Zero(h.DeletedAt)
+	should.Empty(h.DeletedBy)
+
+	patch := host.NewDefaultHost()
+	patch.DeletedAt = 1
+	should.NoError(h.Patch(patch.Resource, patch.Describe))
+	should.Zero(h.DeletedAt)
+}
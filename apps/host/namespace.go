@@ -0,0 +1,19 @@
+package host
+
+const (
+	// 内置的命名空间, 引入命名空间之前的主机都属于该命名空间
+	DefaultNamespace = "default"
+)
+
+// 命名空间是否在允许访问的范围内, allowed为nil时不限制
+func NamespaceAllowed(allowed []string, namespace string) bool {
+	if allowed == nil {
+		return true
+	}
+	for _, item := range allowed {
+		if item == namespace {
+			return true
+		}
+	}
+	return false
+}
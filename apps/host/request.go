@@ -0,0 +1,154 @@
+package host
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 从HTTP请求的query string中解析主机查询参数, 参数不合法时返回400异常
+func NewQueryHostRequestFromHTTP(r *http.Request) (*QueryHostRequest, error) {
+	qs := r.URL.Query()
+	req := NewQueryHostRequest()
+
+	var err error
+	if req.PageSize, err = getInt(qs, "page_size", req.PageSize); err != nil {
+		return nil, err
+	}
+	if req.PageNumber, err = getInt(qs, "page_number", req.PageNumber); err != nil {
+		return nil, err
+	}
+	req.Keywords = qs.Get("keywords")
+
+	// 标签过滤: tag.<key>=<value>
+	for k := range qs {
+		if strings.HasPrefix(k, "tag.") && len(k) > len("tag.") {
+			req.Tags[strings.TrimPrefix(k, "tag.")] = qs.Get(k)
+		}
+	}
+
+	// 结构化过滤, 多个值使用逗号分隔: status=running,stopped
+	for _, item := range getList(qs, "vendor") {
+		v, err := ParseVendor(item)
+		if err != nil {
+			return nil, exception.NewBadRequest("vendor invalid, %s", err)
+		}
+		req.Vendor = append(req.Vendor, v)
+	}
+	req.Region = getList(qs, "region")
+	req.Zone = getList(qs, "zone")
+	req.Status = getList(qs, "status")
+	req.Category = getList(qs, "category")
+	req.Type = getList(qs, "type")
+	req.OSType = getList(qs, "os_type")
+	req.PayType = getList(qs, "pay_type")
+	req.PublicIP = getList(qs, "public_ip")
+	req.PrivateIP = getList(qs, "private_ip")
+	req.SyncAccount = getList(qs, "sync_account")
+	req.Namespace = getList(qs, "namespace")
+
+	// 范围过滤
+	if req.CPUMin, err = getInt(qs, "cpu_min", 0); err != nil {
+		return nil, err
+	}
+	if req.CPUMax, err = getInt(qs, "cpu_max", 0); err != nil {
+		return nil, err
+	}
+	if req.MemoryMin, err = getInt(qs, "memory_min", 0); err != nil {
+		return nil, err
+	}
+	if req.MemoryMax, err = getInt(qs, "memory_max", 0); err != nil {
+		return nil, err
+	}
+
+	// 排序: sort=name,-expire_at
+	if req.Sort, err = ParseSort(qs.Get("sort")); err != nil {
+		return nil, exception.NewBadRequest("sort invalid, %s", err)
+	}
+
+	// 变更检测: describe_changed_since=<13位时间戳>
+	if v := qs.Get("describe_changed_since"); v != "" {
+		if req.DescribeChangedSince, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, exception.NewBadRequest("describe_changed_since must be a timestamp in milliseconds, but got %s", v)
+		}
+	}
+
+	// 回收站: deleted=true
+	if v := qs.Get("deleted"); v != "" {
+		if req.Deleted, err = strconv.ParseBool(v); err != nil {
+			return nil, exception.NewBadRequest("deleted must be a bool, but got %s", v)
+		}
+	}
+
+	// 游标分页: cursor=<next_cursor>, 第一页传空值 cursor=
+	if _, ok := qs["cursor"]; ok {
+		req.UseCursor = true
+		// 游标模式默认不统计总数
+		req.WithTotal = false
+		if v := qs.Get("cursor"); v != "" {
+			if req.Cursor, err = ParseCursor(v); err != nil {
+				return nil, exception.NewBadRequest("cursor invalid, %s", err)
+			}
+		}
+	}
+	if v := qs.Get("with_total"); v != "" {
+		if req.WithTotal, err = strconv.ParseBool(v); err != nil {
+			return nil, exception.NewBadRequest("with_total must be a bool, but got %s", v)
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query host request error, %s", err)
+	}
+	return req, nil
+}
+
+// 从HTTP请求中解析统计参数, 过滤参数和主机列表相同, 分组字段: group_by=vendor,region
+func NewStatsHostRequestFromHTTP(r *http.Request) (*StatsHostRequest, error) {
+	query, err := NewQueryHostRequestFromHTTP(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := NewStatsHostRequest()
+	req.QueryHostRequest = query
+	req.GroupBy = getList(r.URL.Query(), "group_by")
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("%s", err)
+	}
+	return req, nil
+}
+
+// 读取整数参数, 参数不存在时使用默认值
+func getInt(qs url.Values, key string, defaultValue int) (int, error) {
+	v := qs.Get(key)
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, exception.NewBadRequest("%s must be an integer, but got %s", key, v)
+	}
+	return n, nil
+}
+
+// 读取列表参数, 支持逗号分隔和重复传参两种方式
+func getList(qs url.Values, key string) []string {
+	list := []string{}
+	for _, v := range qs[key] {
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+package host_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQueryHostRequestFromHTTP(t *testing.T) {
+	should := assert.New(t)
+
+	r := httptest.NewRequest("GET", "/hosts?page_size=10&vendor=ALI_CLOUD,2&status=running,stopped&cpu_min=2&memory_max=8192&tag.env=prod&sort=name,-expire_at", nil)
+	req, err := host.NewQueryHostRequestFromHTTP(r)
+	if should.NoError(err) {
+		should.Equal(10, req.PageSize)
+		should.Equal(1, req.PageNumber)
+		should.Equal([]host.Vendor{host.ALI_CLOUD, host.HW_CLOUD}, req.Vendor)
+		should.Equal([]string{"running", "stopped"}, req.Status)
+		should.Equal(2, req.CPUMin)
+		should.Equal(8192, req.MemoryMax)
+		should.Equal("prod", req.Tags["env"])
+		should.Equal([]*host.SortBy{{Field: "name"}, {Field: "expire_at", Desc: true}}, req.Sort)
+	}
+}
+
+func TestNewQueryHostRequestFromHTTPInvalid(t *testing.T) {
+	should := assert.New(t)
+
+	for _, qs := range []string{"page_size=abc", "vendor=AWS", "cpu_min=8&cpu_max=2", "memory_min=-1", "sort=password", "cursor=???", "cursor=&sort=name"} {
+		_, err := host.NewQueryHostRequestFromHTTP(httptest.NewRequest("GET", "/hosts?"+qs, nil))
+		if should.Error(err, qs) {
+			e, ok := err.(exception.APIException)
+			should.True(ok && e.ErrorCode() == exception.BadRequest, qs)
+		}
+	}
+}
+
+func TestParseWithin(t *testing.T) {
+	should := assert.New(t)
+
+	d, err := host.ParseWithin("7d")
+	if should.NoError(err) {
+		should.Equal(7*24*time.Hour, d)
+	}
+	d, err = host.ParseWithin("12h")
+	if should.NoError(err) {
+		should.Equal(12*time.Hour, d)
+	}
+	for _, s := range []string{"", "d", "-1d", "0h", "week"} {
+		_, err = host.ParseWithin(s)
+		should.Error(err, s)
+	}
+}
@@ -0,0 +1,146 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/infraboard/mcube/types/ftime"
+)
+
+// 产生版本的操作
+type RevisionAction string
+
+const (
+	ActionCreate = RevisionAction("create")
+	ActionPut    = RevisionAction("put")
+	ActionPatch  = RevisionAction("patch")
+	// 软删除和从回收站恢复
+	ActionDelete  = RevisionAction("delete")
+	ActionRestore = RevisionAction("restore")
+)
+
+// 根据更新模式确定版本操作
+func (m UpdateMode) Action() RevisionAction {
+	if m == PATCH {
+		return ActionPatch
+	}
+	return ActionPut
+}
+
+func NewRevision(ins *Host, action RevisionAction, operator string) *Revision {
+	return &Revision{
+		HostId:   ins.Id,
+		Action:   action,
+		Operator: operator,
+		CreateAt: ftime.Now().Timestamp(),
+		Host:     ins,
+	}
+}
+
+// 主机的一个历史版本, 保存写入后的完整数据
+type Revision struct {
+	HostId   string         `json:"host_id"`
+	Revision int            `json:"revision"`
+	Action   RevisionAction `json:"action"`
+	Operator string         `json:"operator"`
+	CreateAt int64          `json:"create_at"`
+	Host     *Host          `json:"host"`
+}
+
+func NewRevisionSet() *RevisionSet {
+	return &RevisionSet{
+		Items: []*Revision{},
+	}
+}
+
+type RevisionSet struct {
+	Total int64       `json:"total"`
+	Items []*Revision `json:"items"`
+}
+
+func (s *RevisionSet) Add(item *Revision) {
+	s.Items = append(s.Items, item)
+}
+
+// 字段级别的变更
+type FieldChange struct {
+	// 字段名称和JSON Tag保持一致, 标签使用 tags.<key>
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// 两个版本之间的差异
+type RevisionDiff struct {
+	HostId  string         `json:"host_id"`
+	From    *Revision      `json:"from"`
+	To      *Revision      `json:"to"`
+	Changes []*FieldChange `json:"changes"`
+}
+
+func NewRevisionDiff(from, to *Revision) (*RevisionDiff, error) {
+	changes, err := Diff(from.Host, to.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{
+		HostId:  to.HostId,
+		From:    from,
+		To:      to,
+		Changes: changes,
+	}, nil
+}
+
+// 对比两个主机的差异, 按字段名称排序
+func Diff(from, to *Host) ([]*FieldChange, error) {
+	fm, err := flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := flatten(to)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for k := range fm {
+		fields[k] = true
+	}
+	for k := range tm {
+		fields[k] = true
+	}
+
+	changes := []*FieldChange{}
+	for k := range fields {
+		if !reflect.DeepEqual(fm[k], tm[k]) {
+			changes = append(changes, &FieldChange{Field: k, From: fm[k], To: tm[k]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// 把主机转换成 字段 --> 值, 标签展开成 tags.<key>
+func flatten(ins *Host) (map[string]interface{}, error) {
+	data, err := json.Marshal(ins)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	if tags, ok := m["tags"].(map[string]interface{}); ok {
+		for k, v := range tags {
+			m[fmt.Sprintf("tags.%s", k)] = v
+		}
+	}
+	delete(m, "tags")
+	return m, nil
+}
@@ -0,0 +1,144 @@
+package host
+
+import (
+	"strings"
+)
+
+var (
+	// 搜索字段前缀对应的主机字段, 比如 ip:10.0. 同时搜索公网和内网IP
+	searchFieldAlias = map[string][]string{
+		"name":            {"name"},
+		"desc":            {"description"},
+		"description":     {"description"},
+		"instance":        {"instance_id"},
+		"instance_id":     {"instance_id"},
+		"ip":              {"public_ip", "private_ip"},
+		"public_ip":       {"public_ip"},
+		"private_ip":      {"private_ip"},
+		"sn":              {"serial_number"},
+		"serial_number":   {"serial_number"},
+		"sg":              {"security_groups"},
+		"security_groups": {"security_groups"},
+	}
+
+	// 不带前缀时搜索的字段
+	defaultSearchFields = []string{
+		"name", "description", "instance_id", "public_ip", "private_ip", "serial_number", "security_groups",
+	}
+)
+
+// 一个搜索条件, 多个条件之间是与的关系, 一个条件的多个字段之间是或的关系
+type SearchTerm struct {
+	// 字段前缀, 为空表示搜索所有字段
+	Prefix string
+	// 搜索的字段
+	Fields []string
+	// 搜索的内容, 字段包含该内容即匹配
+	Value string
+	// 使用双引号括起来的短语, 需要整体匹配
+	Phrase bool
+}
+
+// 解析搜索关键字, 支持:
+//   - 多个关键字使用空格分隔: web prod
+//   - 双引号括起来的短语: "web server"
+//   - 字段前缀: ip:10.0. name:"web 01"
+//
+// 未知的前缀当作普通关键字, 未闭合的引号一直到结尾
+func ParseKeywords(s string) []*SearchTerm {
+	terms := []*SearchTerm{}
+	for _, token := range splitKeywords(s) {
+		term := &SearchTerm{Fields: defaultSearchFields, Value: token}
+		if idx := strings.Index(token, ":"); idx > 0 {
+			if fields, ok := searchFieldAlias[strings.ToLower(token[:idx])]; ok {
+				term.Prefix = strings.ToLower(token[:idx])
+				term.Fields = fields
+				term.Value = token[idx+1:]
+			}
+		}
+		if strings.HasPrefix(term.Value, `"`) {
+			term.Phrase = true
+			term.Value = strings.Trim(term.Value, `"`)
+		}
+		if term.Value == "" {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// 按空格切分, 引号内的空格不切分
+func splitKeywords(s string) []string {
+	tokens := []string{}
+	b := &strings.Builder{}
+	quoted := false
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			b.WriteRune(c)
+		case c == ' ' && !quoted:
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens
+}
+
+// 主机搜索字段的值
+func (h *Host) SearchValue(field string) string {
+	switch field {
+	case "name":
+		return h.Name
+	case "description":
+		return h.Description
+	case "instance_id":
+		return h.InstanceId
+	case "public_ip":
+		return h.PublicIP
+	case "private_ip":
+		return h.PrivateIP
+	case "serial_number":
+		return h.SerialNumber
+	case "security_groups":
+		return h.SecurityGroups
+	}
+	return ""
+}
+
+// 主机是否满足所有搜索条件, 忽略大小写
+func (h *Host) MatchSearch(terms []*SearchTerm) bool {
+	for _, t := range terms {
+		found := false
+		for _, f := range t.Fields {
+			if strings.Contains(strings.ToLower(h.SearchValue(f)), strings.ToLower(t.Value)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// 有字段和某个搜索条件完全相同时为精确匹配, 精确匹配的结果排在前面
+func (h *Host) ExactMatch(terms []*SearchTerm) bool {
+	for _, t := range terms {
+		for _, f := range t.Fields {
+			if strings.EqualFold(h.SearchValue(f), t.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package host_test
+
+import (
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeywords(t *testing.T) {
+	should := assert.New(t)
+
+	terms := host.ParseKeywords(`web  ip:10.0. name:"web 01" "prod server" foo:bar ip:`)
+	if should.Len(terms, 5) {
+		should.Equal("web", terms[0].Value)
+		should.Equal("", terms[0].Prefix)
+
+		should.Equal("10.0.", terms[1].Value)
+		should.Equal([]string{"public_ip", "private_ip"}, terms[1].Fields)
+
+		should.Equal("web 01", terms[2].Value)
+		should.Equal([]string{"name"}, terms[2].Fields)
+		should.True(terms[2].Phrase)
+
+		should.Equal("prod server", terms[3].Value)
+		should.True(terms[3].Phrase)
+
+		// 未知的前缀当作普通关键字
+		should.Equal("foo:bar", terms[4].Value)
+	}
+}
@@ -0,0 +1,78 @@
+package host
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// 允许排序的字段, 字段名称和JSON Tag保持一致
+	sortableFields = map[string]bool{
+		// Resource
+		"namespace":   true,
+		"vendor":      true,
+		"region":      true,
+		"zone":        true,
+		"create_at":   true,
+		"expire_at":   true,
+		"update_at":   true,
+		"sync_at":     true,
+		"category":    true,
+		"type":        true,
+		"instance_id": true,
+		"name":        true,
+		"status":      true,
+		"public_ip":   true,
+		"private_ip":  true,
+		"pay_type":    true,
+		// Describe
+		"cpu":                        true,
+		"memory":                     true,
+		"gpu_amount":                 true,
+		"os_type":                    true,
+		"os_name":                    true,
+		"internet_max_bandwidth_out": true,
+		"internet_max_bandwidth_in":  true,
+	}
+)
+
+// 排序字段
+type SortBy struct {
+	Field string
+	Desc  bool
+}
+
+func (s *SortBy) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// 解析排序参数, 格式: name,-expire_at, 字段前加 - 表示倒序
+func ParseSort(s string) ([]*SortBy, error) {
+	sorts := []*SortBy{}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		sb := &SortBy{Field: item}
+		if strings.HasPrefix(item, "-") {
+			sb.Field = strings.TrimPrefix(item, "-")
+			sb.Desc = true
+		}
+		sb.Field = strings.TrimPrefix(sb.Field, "+")
+		if !sortableFields[sb.Field] {
+			return nil, fmt.Errorf("field %s is not sortable", sb.Field)
+		}
+		if seen[sb.Field] {
+			return nil, fmt.Errorf("sort field %s duplicated", sb.Field)
+		}
+		seen[sb.Field] = true
+		sorts = append(sorts, sb)
+	}
+	return sorts, nil
+}
@@ -0,0 +1,132 @@
+package host
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// 支持分组统计的字段
+	statsGroupFields = map[string]bool{
+		"namespace": true,
+		"vendor":    true,
+		"region":    true,
+		"zone":      true,
+		"status":    true,
+		"os_type":   true,
+		"pay_type":  true,
+	}
+)
+
+func NewStatsHostRequest() *StatsHostRequest {
+	return &StatsHostRequest{
+		QueryHostRequest: NewQueryHostRequest(),
+		GroupBy:          []string{},
+	}
+}
+
+// 过滤条件和QueryHost相同, 分页参数不生效
+type StatsHostRequest struct {
+	*QueryHostRequest
+	// 分组字段, 为空时只统计总数
+	GroupBy []string
+}
+
+func (req *StatsHostRequest) Validate() error {
+	if err := req.QueryHostRequest.Validate(); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, f := range req.GroupBy {
+		if !statsGroupFields[f] {
+			return fmt.Errorf("field %s can not be grouped by", f)
+		}
+		if seen[f] {
+			return fmt.Errorf("group by field %s duplicated", f)
+		}
+		seen[f] = true
+	}
+	return nil
+}
+
+// 主机的分组字段值
+func (h *Host) GroupValue(field string) string {
+	switch field {
+	case "namespace":
+		return h.Namespace
+	case "vendor":
+		return h.Vendor.String()
+	case "region":
+		return h.Region
+	case "zone":
+		return h.Zone
+	case "status":
+		return h.Status
+	case "os_type":
+		return h.OSType
+	case "pay_type":
+		return h.PayType
+	}
+	return ""
+}
+
+// 一个分组的统计结果
+type HostStats struct {
+	// 分组字段 --> 字段值, 不分组时为空
+	Group     map[string]string `json:"group,omitempty"`
+	Count     int64             `json:"count"`
+	CPU       int64             `json:"cpu"`
+	Memory    int64             `json:"memory"`
+	GPUAmount int64             `json:"gpu_amount"`
+}
+
+func (s *HostStats) Add(ins *Host) {
+	s.Count++
+	s.CPU += int64(ins.CPU)
+	s.Memory += int64(ins.Memory)
+	s.GPUAmount += int64(ins.GPUAmount)
+}
+
+func (s *HostStats) merge(other *HostStats) {
+	s.Count += other.Count
+	s.CPU += other.CPU
+	s.Memory += other.Memory
+	s.GPUAmount += other.GPUAmount
+}
+
+func NewStatsSet(groupBy []string) *StatsSet {
+	return &StatsSet{
+		GroupBy: groupBy,
+		Total:   &HostStats{},
+		Items:   []*HostStats{},
+	}
+}
+
+type StatsSet struct {
+	GroupBy []string `json:"group_by"`
+	// 所有分组的合计
+	Total *HostStats   `json:"total"`
+	Items []*HostStats `json:"items"`
+}
+
+func (s *StatsSet) Add(item *HostStats) {
+	s.Items = append(s.Items, item)
+	s.Total.merge(item)
+}
+
+// 按分组字段的值排序, 保证不同存储的返回顺序一致
+func (s *StatsSet) Sort() {
+	sort.SliceStable(s.Items, func(i, j int) bool {
+		return s.key(s.Items[i]) < s.key(s.Items[j])
+	})
+}
+
+func (s *StatsSet) key(item *HostStats) string {
+	values := make([]string, 0, len(s.GroupBy))
+	for _, f := range s.GroupBy {
+		values = append(values, item.Group[f])
+	}
+	return strings.Join(values, "\x00")
+}
@@ -1,7 +1,25 @@
 package apps
 
-import "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+)
 
 var (
-	Host host.Service
+	Host     host.Service
+	Sync     syncer.Service
+	Reminder reminder.Service
+	// 只在MySQL存储时提供
+	Account account.Service
+	User    user.Service
+	Policy  policy.Service
+	APIKey  apikey.Service
+	// 命名空间, 用于限制主机的访问范围
+	Namespace namespace.Service
 )
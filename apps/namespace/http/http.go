@@ -0,0 +1,38 @@
+package http
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// Namespace 模块的 HTTP API 服务实例
+var API = handler{}
+
+type handler struct {
+	namespace namespace.Service
+	log       logger.Logger
+}
+
+func (h *handler) Init() {
+	h.log = zap.L().Named("NAMESPACE API")
+
+	if apps.Namespace == nil {
+		panic("dependence namespace service is nil")
+	}
+	h.namespace = apps.Namespace
+}
+
+func (h *handler) Registry(r *router.Router) {
+	r.POST("/namespaces", permission.Required(policy.NamespaceWrite, h.CreateNamespace))
+	// 非管理员只能看到自己是成员的命名空间
+	r.GET("/namespaces", permission.Required(policy.NamespaceRead, h.QueryNamespace))
+	r.GET("/namespaces/:name", permission.Required(policy.NamespaceRead, h.DescribeNamespace))
+	r.PATCH("/namespaces/:name", permission.Required(policy.NamespaceWrite, h.UpdateNamespace))
+	r.DELETE("/namespaces/:name", permission.Required(policy.NamespaceWrite, h.DeleteNamespace))
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/request"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+func (h *handler) CreateNamespace(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := namespace.NewCreateNamespaceRequest()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.namespace.CreateNamespace(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 查询命名空间列表, 参数: page_size, page_number, member
+func (h *handler) QueryNamespace(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	qs := r.URL.Query()
+	req := namespace.NewQueryNamespaceRequest()
+
+	var err error
+	if req.PageSize, err = getInt(qs.Get("page_size"), "page_size", req.PageSize); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	if req.PageNumber, err = getInt(qs.Get("page_number"), "page_number", req.PageNumber); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	req.Member = qs.Get("member")
+
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	// 访问范围受限时只能查询自己是成员的命名空间
+	if allowed != nil {
+		req.Member = user.FromContext(r.Context()).Username
+	}
+
+	set, err := h.namespace.QueryNamespace(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
+
+func (h *handler) DescribeNamespace(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	name := ps.ByName("name")
+	allowed, err := permission.AllowedNamespaces(r)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	// 不是成员时和不存在一样处理, 避免泄露其他团队的命名空间
+	if !host.NamespaceAllowed(allowed, name) {
+		response.Failed(w, exception.NewNotFound("namespace %s not found", name))
+		return
+	}
+
+	ins, err := h.namespace.DescribeNamespace(r.Context(), namespace.NewDescribeNamespaceRequest(name))
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 部分更新命名空间, 为空的字段不修改, members会整体替换
+func (h *handler) UpdateNamespace(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req := namespace.NewUpdateNamespaceRequest(ps.ByName("name"))
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.namespace.UpdateNamespace(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 删除命名空间, 命名空间下还有主机时返回409
+func (h *handler) DeleteNamespace(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ins, err := h.namespace.DeleteNamespace(r.Context(), namespace.NewDeleteNamespaceRequest(ps.ByName("name")))
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+func getInt(v, name string, defaultValue int) (int, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, exception.NewBadRequest("%s must be a positive integer, but got %s", name, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,70 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+	"github.com/infraboard/mcube/types/ftime"
+)
+
+// 命名空间服务, 删除时需要检查主机, 需要在host服务之后初始化
+var Service *impl = &impl{}
+
+type impl struct {
+	log   logger.Logger
+	host  host.Service
+	store namespace.Store
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("Namespace")
+
+	if apps.Host == nil {
+		return fmt.Errorf("dependence host service is nil")
+	}
+	i.host = apps.Host
+
+	// 命名空间和主机数据使用相同的存储
+	switch conf.C().App.Storage {
+	case conf.MySQLStorage, "":
+		db, err := conf.C().MySQL.GetDB()
+		if err != nil {
+			return err
+		}
+		i.store = newMySQLStore(db)
+	case conf.MemoryStorage:
+		i.store = newMemoryStore()
+	default:
+		return fmt.Errorf("unknown storage type %s", conf.C().App.Storage)
+	}
+	return i.bootstrap(context.Background())
+}
+
+// 内置的命名空间不存在时创建, MySQL存储时由迁移脚本创建
+func (i *impl) bootstrap(ctx context.Context) error {
+	_, err := i.store.GetNamespace(ctx, namespace.DefaultNamespace)
+	if err == nil || !exception.IsNotFoundError(err) {
+		return err
+	}
+
+	now := ftime.Now().Timestamp()
+	ins := &namespace.Namespace{
+		Name:        namespace.DefaultNamespace,
+		DisplayName: "Default",
+		Members:     []string{},
+		CreateAt:    now,
+		UpdateAt:    now,
+	}
+	if err := i.store.SaveNamespace(ctx, ins); err != nil && !exception.IsConflictError(err) {
+		return fmt.Errorf("create default namespace error, %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package impl
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+)
+
+func (i *impl) CreateNamespace(ctx context.Context, req *namespace.CreateNamespaceRequest) (*namespace.Namespace, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate create namespace request error, %s", err)
+	}
+
+	now := ftime.Now().Timestamp()
+	ins := &namespace.Namespace{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Members:     namespace.UniqueMembers(req.Members),
+		CreateAt:    now,
+		UpdateAt:    now,
+	}
+	if err := i.store.SaveNamespace(ctx, ins); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (i *impl) QueryNamespace(ctx context.Context, req *namespace.QueryNamespaceRequest) (*namespace.Set, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate query namespace request error, %s", err)
+	}
+	return i.store.QueryNamespace(ctx, req)
+}
+
+func (i *impl) DescribeNamespace(ctx context.Context, req *namespace.DescribeNamespaceRequest) (*namespace.Namespace, error) {
+	if req.Name == "" {
+		return nil, exception.NewBadRequest("namespace name required")
+	}
+	return i.store.GetNamespace(ctx, req.Name)
+}
+
+func (i *impl) UpdateNamespace(ctx context.Context, req *namespace.UpdateNamespaceRequest) (*namespace.Namespace, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate update namespace request error, %s", err)
+	}
+
+	ins, err := i.store.GetNamespace(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if req.DisplayName != "" {
+		ins.DisplayName = req.DisplayName
+	}
+	if req.Description != "" {
+		ins.Description = req.Description
+	}
+	if req.Members != nil {
+		ins.Members = namespace.UniqueMembers(req.Members)
+	}
+	ins.UpdateAt = ftime.Now().Timestamp()
+
+	if err := i.store.UpdateNamespace(ctx, ins); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (i *impl) DeleteNamespace(ctx context.Context, req *namespace.DeleteNamespaceRequest) (*namespace.Namespace, error) {
+	if req.Name == namespace.DefaultNamespace {
+		return nil, exception.NewBadRequest("namespace %s is builtin, can not be deleted", req.Name)
+	}
+
+	ins, err := i.store.GetNamespace(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 回收站中的主机恢复后仍然属于该命名空间, 也需要先清理
+	for _, deleted := range []bool{false, true} {
+		query := host.NewQueryHostRequest()
+		query.PageSize = 1
+		query.Namespace = []string{ins.Name}
+		query.Deleted = deleted
+		set, err := i.host.QueryHost(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		if set.Total > 0 {
+			return nil, exception.NewConflict("namespace %s still has %d hosts, delete them first", ins.Name, set.Total)
+		}
+	}
+
+	if err := i.store.DeleteNamespace(ctx, ins.Name); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (i *impl) ListMemberNamespace(ctx context.Context, req *namespace.ListMemberNamespaceRequest) ([]string, error) {
+	if req.Username == "" {
+		return []string{}, nil
+	}
+	return i.store.ListMemberNamespace(ctx, req.Username)
+}
@@ -0,0 +1,82 @@
+package impl_test
+
+import (
+	"context"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	cfg := conf.NewDefaultConfig()
+	cfg.App.Storage = conf.MemoryStorage
+	conf.SetGlobalConfig(cfg)
+	should.NoError(memory.Service.Init())
+	apps.Host = memory.Service
+	if !should.NoError(impl.Service.Init()) {
+		return
+	}
+
+	// 内置的命名空间自动创建
+	_, err := impl.Service.DescribeNamespace(ctx, namespace.NewDescribeNamespaceRequest(namespace.DefaultNamespace))
+	should.NoError(err)
+
+	req := namespace.NewCreateNamespaceRequest()
+	req.Name = "team-a"
+	req.Members = []string{"alice", "bob", "alice"}
+	ins, err := impl.Service.CreateNamespace(ctx, req)
+	if should.NoError(err) {
+		should.Equal([]string{"alice", "bob"}, ins.Members)
+	}
+	_, err = impl.Service.CreateNamespace(ctx, req)
+	should.True(exception.IsConflictError(err))
+
+	req.Name = "Team_B"
+	_, err = impl.Service.CreateNamespace(ctx, req)
+	should.Error(err)
+
+	names, err := impl.Service.ListMemberNamespace(ctx, namespace.NewListMemberNamespaceRequest("alice"))
+	if should.NoError(err) {
+		should.Equal([]string{"team-a"}, names)
+	}
+	names, err = impl.Service.ListMemberNamespace(ctx, namespace.NewListMemberNamespaceRequest("carol"))
+	if should.NoError(err) {
+		should.NotNil(names)
+		should.Empty(names)
+	}
+
+	// 命名空间下还有主机时不允许删除
+	h := host.NewDefaultHost()
+	h.Namespace = "team-a"
+	h.Region = "hangzhou"
+	h.Type = "sm1"
+	h.Name = "host01"
+	h.CPU = 1
+	h.Memory = 2048
+	created, err := memory.Service.CreateHost(ctx, h)
+	if !should.NoError(err) {
+		return
+	}
+	_, err = impl.Service.DeleteNamespace(ctx, namespace.NewDeleteNamespaceRequest("team-a"))
+	should.True(exception.IsConflictError(err))
+
+	// 回收站中的主机同样阻止删除
+	_, err = memory.Service.DeleteHost(ctx, &host.DeleteHostRequest{Id: created.Id})
+	should.NoError(err)
+	_, err = impl.Service.DeleteNamespace(ctx, namespace.NewDeleteNamespaceRequest("team-a"))
+	should.True(exception.IsConflictError(err))
+
+	_, err = impl.Service.DeleteNamespace(ctx, namespace.NewDeleteNamespaceRequest(namespace.DefaultNamespace))
+	should.Error(err)
+}
@@ -0,0 +1,256 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+const (
+	// MySQL 唯一索引冲突的错误码
+	errDuplicateEntry = 1062
+
+	insertNamespaceSQL = `INSERT INTO namespace (name, display_name, description, members, create_at, update_at) VALUES (?,?,?,?,?,?)`
+	queryNamespaceSQL  = `SELECT name, display_name, description, members, create_at, update_at FROM namespace`
+	countNamespaceSQL  = `SELECT COUNT(*) FROM namespace`
+	updateNamespaceSQL = `UPDATE namespace SET display_name=?, description=?, members=?, update_at=? WHERE name=?`
+	deleteNamespaceSQL = `DELETE FROM namespace WHERE name=?`
+	memberNamespaceSQL = `SELECT name FROM namespace WHERE FIND_IN_SET(?, members) > 0 ORDER BY name`
+)
+
+func newMySQLStore(db *sql.DB) *mysqlStore {
+	return &mysqlStore{db: db}
+}
+
+type mysqlStore struct {
+	db *sql.DB
+}
+
+func (s *mysqlStore) SaveNamespace(ctx context.Context, n *namespace.Namespace) error {
+	_, err := s.db.ExecContext(ctx, insertNamespaceSQL,
+		n.Name, n.DisplayName, n.Description, strings.Join(n.Members, ","), n.CreateAt, n.UpdateAt,
+	)
+	if err != nil {
+		var e *mysql.MySQLError
+		if errors.As(err, &e) && e.Number == errDuplicateEntry {
+			return exception.NewConflict("namespace %s already exists", n.Name)
+		}
+		return fmt.Errorf("save namespace error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) QueryNamespace(ctx context.Context, req *namespace.QueryNamespaceRequest) (*namespace.Set, error) {
+	query := sqlbuilder.NewQuery(queryNamespaceSQL)
+	count := sqlbuilder.NewQuery(countNamespaceSQL)
+	if req.Member != "" {
+		query.Where("FIND_IN_SET(?, members) > 0", req.Member)
+		count.Where("FIND_IN_SET(?, members) > 0", req.Member)
+	}
+	query.Order("name").Asc().Limit(int64(req.Offset()), uint(req.PageSize))
+
+	sqlStr, args := query.BuildQuery()
+	rows, err := s.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query namespace error, %s", err)
+	}
+	defer rows.Close()
+
+	set := namespace.NewSet()
+	for rows.Next() {
+		n, err := scanNamespace(rows)
+		if err != nil {
+			return nil, err
+		}
+		set.Add(n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args = count.BuildQuery()
+	if err := s.db.QueryRowContext(ctx, sqlStr, args...).Scan(&set.Total); err != nil {
+		return nil, fmt.Errorf("count namespace error, %s", err)
+	}
+	return set, nil
+}
+
+func (s *mysqlStore) GetNamespace(ctx context.Context, name string) (*namespace.Namespace, error) {
+	query := sqlbuilder.NewQuery(queryNamespaceSQL)
+	query.Where("name = ?", name)
+	sqlStr, args := query.BuildQuery()
+
+	n, err := scanNamespace(s.db.QueryRowContext(ctx, sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewNotFound("namespace %s not found", name)
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+func (s *mysqlStore) UpdateNamespace(ctx context.Context, n *namespace.Namespace) error {
+	_, err := s.db.ExecContext(ctx, updateNamespaceSQL,
+		n.DisplayName, n.Description, strings.Join(n.Members, ","), n.UpdateAt, n.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("update namespace error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) DeleteNamespace(ctx context.Context, name string) error {
+	if _, err := s.db.ExecContext(ctx, deleteNamespaceSQL, name); err != nil {
+		return fmt.Errorf("delete namespace error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) ListMemberNamespace(ctx context.Context, username string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, memberNamespaceSQL, username)
+	if err != nil {
+		return nil, fmt.Errorf("query member namespace error, %s", err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNamespace(row scanner) (*namespace.Namespace, error) {
+	var (
+		n       = &namespace.Namespace{}
+		members string
+	)
+	err := row.Scan(&n.Name, &n.DisplayName, &n.Description, &members, &n.CreateAt, &n.UpdateAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan namespace error, %s", err)
+	}
+	n.Members = splitMembers(members)
+	return n, nil
+}
+
+// 成员使用逗号分隔保存
+func splitMembers(s string) []string {
+	members := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item != "" {
+			members = append(members, item)
+		}
+	}
+	return members
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		items: map[string]*namespace.Namespace{},
+	}
+}
+
+// 基于内存的存储, 服务重启后数据丢失
+type memoryStore struct {
+	lock  sync.Mutex
+	items map[string]*namespace.Namespace
+}
+
+func (s *memoryStore) SaveNamespace(ctx context.Context, n *namespace.Namespace) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.items[n.Name]; ok {
+		return exception.NewConflict("namespace %s already exists", n.Name)
+	}
+	s.items[n.Name] = copyNamespace(n)
+	return nil
+}
+
+func (s *memoryStore) QueryNamespace(ctx context.Context, req *namespace.QueryNamespaceRequest) (*namespace.Set, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	items := []*namespace.Namespace{}
+	for _, n := range s.items {
+		if req.Member != "" && !n.HasMember(req.Member) {
+			continue
+		}
+		items = append(items, copyNamespace(n))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	set := namespace.NewSet()
+	set.Total = int64(len(items))
+	for idx := req.Offset(); idx < len(items) && idx < req.Offset()+req.PageSize; idx++ {
+		set.Add(items[idx])
+	}
+	return set, nil
+}
+
+func (s *memoryStore) GetNamespace(ctx context.Context, name string) (*namespace.Namespace, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.items[name]
+	if !ok {
+		return nil, exception.NewNotFound("namespace %s not found", name)
+	}
+	return copyNamespace(n), nil
+}
+
+func (s *memoryStore) UpdateNamespace(ctx context.Context, n *namespace.Namespace) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.items[n.Name]; ok {
+		s.items[n.Name] = copyNamespace(n)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteNamespace(ctx context.Context, name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.items, name)
+	return nil
+}
+
+func (s *memoryStore) ListMemberNamespace(ctx context.Context, username string) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	names := []string{}
+	for _, n := range s.items {
+		if n.HasMember(username) {
+			names = append(names, n.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func copyNamespace(n *namespace.Namespace) *namespace.Namespace {
+	cp := *n
+	cp.Members = append([]string{}, n.Members...)
+	return &cp
+}
@@ -0,0 +1,122 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+)
+
+// 命名空间管理
+type Service interface {
+	CreateNamespace(context.Context, *CreateNamespaceRequest) (*Namespace, error)
+	QueryNamespace(context.Context, *QueryNamespaceRequest) (*Set, error)
+	DescribeNamespace(context.Context, *DescribeNamespaceRequest) (*Namespace, error)
+	UpdateNamespace(context.Context, *UpdateNamespaceRequest) (*Namespace, error)
+	// 命名空间下还有主机(包括回收站中的)时不允许删除
+	DeleteNamespace(context.Context, *DeleteNamespaceRequest) (*Namespace, error)
+	// 用户是成员的所有命名空间名称, 用于限制主机的访问范围
+	ListMemberNamespace(context.Context, *ListMemberNamespaceRequest) ([]string, error)
+}
+
+func NewCreateNamespaceRequest() *CreateNamespaceRequest {
+	return &CreateNamespaceRequest{
+		Members: []string{},
+	}
+}
+
+type CreateNamespaceRequest struct {
+	Name        string   `json:"name" validate:"required"`
+	DisplayName string   `json:"display_name" validate:"lte=120"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"`
+}
+
+func (req *CreateNamespaceRequest) Validate() error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	if err := ValidateName(req.Name); err != nil {
+		return err
+	}
+	return validateMembers(req.Members)
+}
+
+func NewQueryNamespaceRequest() *QueryNamespaceRequest {
+	return &QueryNamespaceRequest{
+		PageSize:   20,
+		PageNumber: 1,
+	}
+}
+
+type QueryNamespaceRequest struct {
+	PageSize   int
+	PageNumber int
+	// 按成员过滤
+	Member string
+}
+
+func (req *QueryNamespaceRequest) Validate() error {
+	if req.PageSize <= 0 || req.PageNumber <= 0 {
+		return fmt.Errorf("page_size and page_number must be positive")
+	}
+	return nil
+}
+
+func (req *QueryNamespaceRequest) Offset() int {
+	return (req.PageNumber - 1) * req.PageSize
+}
+
+func NewDescribeNamespaceRequest(name string) *DescribeNamespaceRequest {
+	return &DescribeNamespaceRequest{Name: name}
+}
+
+type DescribeNamespaceRequest struct {
+	Name string
+}
+
+func NewUpdateNamespaceRequest(name string) *UpdateNamespaceRequest {
+	return &UpdateNamespaceRequest{Name: name}
+}
+
+// 部分更新, 为空的字段不修改, members传空数组表示清空成员
+type UpdateNamespaceRequest struct {
+	Name        string   `json:"-"`
+	DisplayName string   `json:"display_name" validate:"lte=120"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"`
+}
+
+func (req *UpdateNamespaceRequest) Validate() error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	return validateMembers(req.Members)
+}
+
+func NewDeleteNamespaceRequest(name string) *DeleteNamespaceRequest {
+	return &DeleteNamespaceRequest{Name: name}
+}
+
+type DeleteNamespaceRequest struct {
+	Name string
+}
+
+func NewListMemberNamespaceRequest(username string) *ListMemberNamespaceRequest {
+	return &ListMemberNamespaceRequest{Username: username}
+}
+
+type ListMemberNamespaceRequest struct {
+	Username string
+}
+
+// 命名空间的存储
+type Store interface {
+	// 名称已经存在时返回Conflict异常
+	SaveNamespace(context.Context, *Namespace) error
+	QueryNamespace(context.Context, *QueryNamespaceRequest) (*Set, error)
+	// 不存在时返回NotFound异常
+	GetNamespace(ctx context.Context, name string) (*Namespace, error)
+	UpdateNamespace(context.Context, *Namespace) error
+	DeleteNamespace(ctx context.Context, name string) error
+	// 用户是成员的命名空间名称
+	ListMemberNamespace(ctx context.Context, username string) ([]string, error)
+}
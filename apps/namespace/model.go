@@ -0,0 +1,91 @@
+package namespace
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate = validator.New()
+	// 和DNS label的规则一致: 小写字母、数字和中划线, 不能以中划线开头或结尾
+	nameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+const (
+	// 内置的命名空间, 不允许删除
+	DefaultNamespace = host.DefaultNamespace
+)
+
+// 命名空间, 用于按团队或者项目隔离主机, 非管理员只能访问自己是成员的命名空间
+type Namespace struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	// 成员的用户名
+	Members  []string `json:"members"`
+	CreateAt int64    `json:"create_at"`
+	UpdateAt int64    `json:"update_at"`
+}
+
+// 用户是否是该命名空间的成员
+func (n *Namespace) HasMember(username string) bool {
+	for _, m := range n.Members {
+		if m == username {
+			return true
+		}
+	}
+	return false
+}
+
+// 校验命名空间名称
+func ValidateName(name string) error {
+	if len(name) > 63 || !nameRegexp.MatchString(name) {
+		return fmt.Errorf("namespace name %s invalid, must be lower case letters, digits or '-', at most 63 characters", name)
+	}
+	return nil
+}
+
+// 校验成员列表, 成员使用逗号分隔保存, 用户名中不能包含逗号
+func validateMembers(members []string) error {
+	for _, m := range members {
+		if strings.TrimSpace(m) == "" || strings.Contains(m, ",") {
+			return fmt.Errorf("member %q invalid", m)
+		}
+	}
+	return nil
+}
+
+// 去掉重复的成员, 保持原有顺序
+func UniqueMembers(members []string) []string {
+	items := make([]string, 0, len(members))
+	seen := map[string]bool{}
+	for _, m := range members {
+		m = strings.TrimSpace(m)
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		items = append(items, m)
+	}
+	return items
+}
+
+func NewSet() *Set {
+	return &Set{
+		Items: []*Namespace{},
+	}
+}
+
+type Set struct {
+	Total int64        `json:"total"`
+	Items []*Namespace `json:"items"`
+}
+
+func (s *Set) Add(item *Namespace) {
+	s.Items = append(s.Items, item)
+}
@@ -0,0 +1,3 @@
+package impl
+
+var LoadPolicies = loadPolicies
@@ -0,0 +1,61 @@
+package impl
+
+import (
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+var Service *impl = &impl{}
+
+type impl struct {
+	log   logger.Logger
+	store policy.Store
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("Policy")
+
+	policies, err := loadPolicies(conf.C().Auth.Policies)
+	if err != nil {
+		return err
+	}
+	i.store = newMemoryStore(policies)
+	return nil
+}
+
+// 在内置权限的基础上, 使用配置的角色权限覆盖
+func loadPolicies(cs []*conf.RolePolicy) ([]*policy.Policy, error) {
+	policies := policy.DefaultPolicies()
+	for _, c := range cs {
+		role, err := user.ParseRole(c.Role)
+		if err != nil {
+			return nil, fmt.Errorf("auth policy error, %s", err)
+		}
+
+		p := &policy.Policy{Role: role}
+		for _, perm := range c.Permissions {
+			// 配置错误的权限直接启动失败, 避免静默地不生效
+			if err := policy.Permission(perm).Validate(); err != nil {
+				return nil, fmt.Errorf("auth policy %s error, %s", c.Role, err)
+			}
+			p.Permissions = append(p.Permissions, policy.Permission(perm))
+		}
+
+		replaced := false
+		for idx := range policies {
+			if policies[idx].Role == role {
+				policies[idx], replaced = p, true
+			}
+		}
+		if !replaced {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
@@ -0,0 +1,24 @@
+package impl_test
+
+import (
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPolicies(t *testing.T) {
+	should := assert.New(t)
+
+	_, err := impl.LoadPolicies([]*conf.RolePolicy{
+		{Role: "viewer", Permissions: []string{"host:read", "namespace:*"}},
+	})
+	should.NoError(err)
+
+	_, err = impl.LoadPolicies([]*conf.RolePolicy{
+		{Role: "viewer", Permissions: []string{"host:raed"}},
+	})
+	should.Error(err)
+}
@@ -0,0 +1,36 @@
+package impl
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+func (i *impl) CheckPermission(ctx context.Context, req *policy.CheckPermissionRequest) error {
+	if req.User == nil {
+		return exception.NewUnauthorized("authentication required")
+	}
+
+	p, err := i.store.GetPolicy(ctx, req.User.Role)
+	if err != nil {
+		// 未配置权限的角色, 没有任何权限
+		if exception.IsNotFoundError(err) {
+			return exception.NewPermissionDeny("role %s has no permission %s", req.User.Role, req.Permission)
+		}
+		return err
+	}
+	if !p.Allow(req.Permission) {
+		return exception.NewPermissionDeny("user %s (role %s) has no permission %s",
+			req.User.Username, req.User.Role, req.Permission)
+	}
+	if req.Scopes != nil && !policy.Allowed(req.Scopes, req.Permission) {
+		return exception.NewPermissionDeny("api key scopes do not include permission %s", req.Permission)
+	}
+	return nil
+}
+
+func (i *impl) QueryPolicy(ctx context.Context) ([]*policy.Policy, error) {
+	return i.store.QueryPolicy(ctx)
+}
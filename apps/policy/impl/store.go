@@ -0,0 +1,42 @@
+package impl
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 角色权限来自内置规则和配置文件, 启动后不再变化
+func newMemoryStore(policies []*policy.Policy) *memoryStore {
+	s := &memoryStore{policies: map[user.Role]*policy.Policy{}}
+	for _, p := range policies {
+		s.policies[p.Role] = p
+		s.roles = append(s.roles, p.Role)
+	}
+	return s
+}
+
+type memoryStore struct {
+	policies map[user.Role]*policy.Policy
+	// 保持配置的顺序
+	roles []user.Role
+}
+
+func (s *memoryStore) GetPolicy(ctx context.Context, role user.Role) (*policy.Policy, error) {
+	p, ok := s.policies[role]
+	if !ok {
+		return nil, exception.NewNotFound("policy for role %s not found", role)
+	}
+	return p, nil
+}
+
+func (s *memoryStore) QueryPolicy(ctx context.Context) ([]*policy.Policy, error) {
+	set := make([]*policy.Policy, 0, len(s.roles))
+	for _, role := range s.roles {
+		set = append(set, s.policies[role])
+	}
+	return set, nil
+}
@@ -0,0 +1,34 @@
+package policy
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+)
+
+type Service interface {
+	// 检查用户是否拥有权限, 没有权限时返回403异常
+	CheckPermission(context.Context, *CheckPermissionRequest) error
+	// 查询所有角色的权限
+	QueryPolicy(context.Context) ([]*Policy, error)
+}
+
+func NewCheckPermissionRequest(u *user.User, perm Permission) *CheckPermissionRequest {
+	return &CheckPermissionRequest{
+		User:       u,
+		Permission: perm,
+	}
+}
+
+type CheckPermissionRequest struct {
+	User       *user.User
+	Permission Permission
+	// 通过API Key访问时, 权限还要受Key的授权范围限制, 为nil时不限制
+	Scopes []Permission
+}
+
+// 角色权限的存储
+type Store interface {
+	GetPolicy(ctx context.Context, role user.Role) (*Policy, error)
+	QueryPolicy(ctx context.Context) ([]*Policy, error)
+}
@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+)
+
+// 权限, 格式: <资源>:<操作>
+type Permission string
+
+const (
+	HostRead   = Permission("host:read")
+	HostWrite  = Permission("host:write")
+	HostDelete = Permission("host:delete")
+
+	SyncRun = Permission("sync:run")
+
+	AccountRead  = Permission("account:read")
+	AccountWrite = Permission("account:write")
+
+	UserWrite = Permission("user:write")
+
+	APIKeyRead  = Permission("apikey:read")
+	APIKeyWrite = Permission("apikey:write")
+
+	NamespaceRead  = Permission("namespace:read")
+	NamespaceWrite = Permission("namespace:write")
+
+	// 所有权限
+	All = Permission("*")
+)
+
+var (
+	permissions = []Permission{
+		HostRead, HostWrite, HostDelete,
+		SyncRun,
+		AccountRead, AccountWrite,
+		UserWrite,
+		APIKeyRead, APIKeyWrite,
+		NamespaceRead, NamespaceWrite,
+	}
+)
+
+// 校验权限是否存在, 通配符需要匹配至少一个权限
+func (p Permission) Validate() error {
+	for _, item := range permissions {
+		if item.MatchedBy(p) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown permission %s", p)
+}
+
+// 权限是否被授予的权限覆盖, 支持 * 和 host:* 通配
+func (p Permission) MatchedBy(granted Permission) bool {
+	if granted == All || granted == p {
+		return true
+	}
+	if strings.HasSuffix(string(granted), ":*") {
+		return strings.HasPrefix(string(p), strings.TrimSuffix(string(granted), "*"))
+	}
+	return false
+}
+
+// 角色拥有的权限
+type Policy struct {
+	Role        user.Role    `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+func (p *Policy) Allow(perm Permission) bool {
+	return Allowed(p.Permissions, perm)
+}
+
+// 权限是否被授予的权限列表覆盖
+func Allowed(granted []Permission, perm Permission) bool {
+	for _, item := range granted {
+		if perm.MatchedBy(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// 内置的角色权限, 可以通过配置覆盖
+func DefaultPolicies() []*Policy {
+	return []*Policy{
+		{Role: user.RoleViewer, Permissions: []Permission{HostRead, NamespaceRead}},
+		{Role: user.RoleOperator, Permissions: []Permission{HostRead, HostWrite, SyncRun, AccountRead, NamespaceRead}},
+		{Role: user.RoleAdmin, Permissions: []Permission{All}},
+	}
+}
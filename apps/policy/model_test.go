@@ -0,0 +1,37 @@
+package policy_test
+
+import (
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPolicies(t *testing.T) {
+	should := assert.New(t)
+
+	set := map[user.Role]*policy.Policy{}
+	for _, p := range policy.DefaultPolicies() {
+		set[p.Role] = p
+	}
+
+	should.True(set[user.RoleViewer].Allow(policy.HostRead))
+	should.False(set[user.RoleViewer].Allow(policy.HostWrite))
+
+	should.True(set[user.RoleOperator].Allow(policy.HostWrite))
+	should.False(set[user.RoleOperator].Allow(policy.HostDelete))
+
+	should.True(set[user.RoleAdmin].Allow(policy.HostDelete))
+	should.True(set[user.RoleAdmin].Allow(policy.UserWrite))
+}
+
+func TestPermissionMatchedBy(t *testing.T) {
+	should := assert.New(t)
+
+	should.True(policy.HostDelete.MatchedBy("host:*"))
+	should.False(policy.AccountRead.MatchedBy("host:*"))
+	should.False(policy.Permission("hostx:read").MatchedBy("host:*"))
+	should.True(policy.SyncRun.MatchedBy(policy.All))
+}
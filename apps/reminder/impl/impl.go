@@ -0,0 +1,54 @@
+package impl
+
+import (
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder/notify"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// 过期提醒服务, 依赖host服务, 需要在host服务之后初始化
+var Service *impl = &impl{}
+
+type impl struct {
+	log      logger.Logger
+	host     host.Service
+	notifier reminder.Notifier
+	store    reminder.Store
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("Reminder")
+
+	if apps.Host == nil {
+		return fmt.Errorf("dependence host service is nil")
+	}
+	i.host = apps.Host
+
+	n, err := notify.New()
+	if err != nil {
+		return err
+	}
+	i.notifier = n
+
+	// 提醒记录和主机数据使用相同的存储
+	switch conf.C().App.Storage {
+	case conf.MySQLStorage, "":
+		db, err := conf.C().MySQL.GetDB()
+		if err != nil {
+			return err
+		}
+		i.store = newMySQLStore(db)
+	case conf.MemoryStorage:
+		i.store = newMemoryStore()
+	default:
+		return fmt.Errorf("unknown storage type %s", conf.C().App.Storage)
+	}
+	return nil
+}
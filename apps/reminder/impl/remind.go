@@ -0,0 +1,69 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+)
+
+const (
+	// 查询过期主机时的分页大小
+	queryPageSize = 100
+)
+
+func (i *impl) Remind(ctx context.Context, req *reminder.RemindRequest) (*reminder.RemindResponse, error) {
+	if req.Within <= 0 {
+		return nil, exception.NewBadRequest("remind within must be positive")
+	}
+
+	now := ftime.Now().Timestamp()
+	query := host.NewQueryHostRequest()
+	query.ExpireBefore = now + req.Within.Milliseconds()
+	query.PageSize = queryPageSize
+	query.UseCursor = true
+	query.WithTotal = false
+
+	resp := reminder.NewRemindResponse()
+	for {
+		set, err := i.host.QueryHost(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range set.Items {
+			i.remind(ctx, reminder.NewReminder(ins, now), resp)
+		}
+		if set.NextCursor == "" {
+			break
+		}
+		query.Cursor = host.NewCursor(set.Items[len(set.Items)-1])
+	}
+	return resp, nil
+}
+
+// 同一个窗口已经提醒过的跳过, 发送成功后才记录, 失败的下次扫描时重试
+func (i *impl) remind(ctx context.Context, r *reminder.Reminder, resp *reminder.RemindResponse) {
+	sent, err := i.store.Exist(ctx, r)
+	if err != nil {
+		resp.AddError(err)
+		return
+	}
+	if sent {
+		resp.Skipped++
+		return
+	}
+
+	if err := i.notifier.Notify(ctx, r); err != nil {
+		resp.AddError(fmt.Errorf("notify host %s %s error, %s", r.HostId, r.Stage, err))
+		return
+	}
+	if err := i.store.Save(ctx, r); err != nil {
+		resp.AddError(err)
+		return
+	}
+	resp.Sent++
+}
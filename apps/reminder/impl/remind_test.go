@@ -0,0 +1,72 @@
+package impl_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemind(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	// 没有配置邮件服务器, 邮件写入本地目录
+	cfg := conf.NewDefaultConfig()
+	cfg.App.Storage = conf.MemoryStorage
+	cfg.Reminder.Notifier = conf.SMTPNotifier
+	cfg.Reminder.SMTP.To = []string{"ops@example.com"}
+	cfg.Reminder.SMTP.MailboxDir = t.TempDir()
+	conf.SetGlobalConfig(cfg)
+	should.NoError(memory.Service.Init())
+	apps.Host = memory.Service
+	if !should.NoError(impl.Service.Init()) {
+		return
+	}
+
+	now := time.Now()
+	expires := map[string]time.Time{
+		"expired":  now.Add(-time.Hour),
+		"expiring": now.Add(24 * time.Hour),
+		"later":    now.Add(30 * 24 * time.Hour),
+		"never":    time.Unix(0, 0),
+	}
+	for name, expireAt := range expires {
+		ins := host.NewDefaultHost()
+		ins.Namespace = host.DefaultNamespace
+		ins.Region = "hangzhou"
+		ins.Type = "sm1"
+		ins.Name = name
+		ins.CPU = 1
+		ins.Memory = 2048
+		ins.ExpireAt = expireAt.UnixNano() / 1000000
+		_, err := memory.Service.CreateHost(ctx, ins)
+		should.NoError(err)
+	}
+
+	req := reminder.NewRemindRequest(7 * 24 * time.Hour)
+	resp, err := impl.Service.Remind(ctx, req)
+	if should.NoError(err) {
+		should.Equal(2, resp.Sent)
+	}
+	files, err := os.ReadDir(cfg.Reminder.SMTP.MailboxDir)
+	if should.NoError(err) {
+		should.Len(files, 2)
+	}
+
+	// 同一个窗口只提醒一次
+	resp, err = impl.Service.Remind(ctx, req)
+	if should.NoError(err) {
+		should.Equal(0, resp.Sent)
+		should.Equal(2, resp.Skipped)
+	}
+}
@@ -0,0 +1,70 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+)
+
+const (
+	existReminderSQL = `SELECT COUNT(*) FROM host_reminder WHERE resource_id=? AND expire_at=? AND stage=?`
+
+	// 并发扫描时重复记录直接忽略
+	insertReminderSQL = `INSERT IGNORE INTO host_reminder (resource_id, expire_at, stage, create_at) VALUES (?,?,?,?)`
+)
+
+func newMySQLStore(db *sql.DB) *mysqlStore {
+	return &mysqlStore{db: db}
+}
+
+// 提醒记录保存在host_reminder表, 服务重启后不会重复提醒
+type mysqlStore struct {
+	db *sql.DB
+}
+
+func (s *mysqlStore) Exist(ctx context.Context, r *reminder.Reminder) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, existReminderSQL, r.HostId, r.ExpireAt, r.Stage).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("query host reminder error, %s", err)
+	}
+	return n > 0, nil
+}
+
+func (s *mysqlStore) Save(ctx context.Context, r *reminder.Reminder) error {
+	_, err := s.db.ExecContext(ctx, insertReminderSQL, r.HostId, r.ExpireAt, r.Stage, r.CreateAt)
+	if err != nil {
+		return fmt.Errorf("save host reminder error, %s", err)
+	}
+	return nil
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{sent: map[string]bool{}}
+}
+
+// 基于内存的提醒记录, 配合内存存储的host服务使用
+type memoryStore struct {
+	lock sync.Mutex
+	sent map[string]bool
+}
+
+func (s *memoryStore) Exist(ctx context.Context, r *reminder.Reminder) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sent[memoryKey(r)], nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, r *reminder.Reminder) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sent[memoryKey(r)] = true
+	return nil
+}
+
+func memoryKey(r *reminder.Reminder) string {
+	return fmt.Sprintf("%s/%d/%s", r.HostId, r.ExpireAt, r.Stage)
+}
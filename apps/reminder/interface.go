@@ -0,0 +1,54 @@
+package reminder
+
+import (
+	"context"
+	"time"
+)
+
+type Service interface {
+	// 扫描即将过期和已经过期的主机, 发送过期提醒
+	Remind(context.Context, *RemindRequest) (*RemindResponse, error)
+}
+
+func NewRemindRequest(within time.Duration) *RemindRequest {
+	return &RemindRequest{
+		Within: within,
+	}
+}
+
+type RemindRequest struct {
+	// 提前多久提醒
+	Within time.Duration
+}
+
+func NewRemindResponse() *RemindResponse {
+	return &RemindResponse{}
+}
+
+type RemindResponse struct {
+	// 发送成功的提醒数量
+	Sent int `json:"sent"`
+	// 同一个窗口内已经提醒过, 跳过的数量
+	Skipped int `json:"skipped"`
+	// 发送失败的数量, 失败原因记录在Errors中
+	Failed int      `json:"failed"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func (r *RemindResponse) AddError(err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, err.Error())
+}
+
+// 提醒的发送方式
+type Notifier interface {
+	Notify(context.Context, *Reminder) error
+}
+
+// 已经发送的提醒记录, 用于去重
+type Store interface {
+	// 同一个窗口的提醒是否已经发送过
+	Exist(context.Context, *Reminder) (bool, error)
+	// 记录已经发送的提醒
+	Save(context.Context, *Reminder) error
+}
@@ -0,0 +1,65 @@
+package reminder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+// 提醒窗口, 每台主机的每个过期时间在每个窗口只提醒一次, 续费后过期时间变化会重新提醒
+type Stage string
+
+const (
+	// 即将过期
+	StageExpiring = Stage("expiring")
+	// 已经过期
+	StageExpired = Stage("expired")
+)
+
+func NewReminder(ins *host.Host, now int64) *Reminder {
+	stage := StageExpiring
+	if ins.ExpireAt <= now {
+		stage = StageExpired
+	}
+	return &Reminder{
+		HostId:     ins.Id,
+		Vendor:     ins.Vendor,
+		Region:     ins.Region,
+		InstanceId: ins.InstanceId,
+		Name:       ins.Name,
+		ExpireAt:   ins.ExpireAt,
+		Stage:      stage,
+		CreateAt:   now,
+	}
+}
+
+// 一条主机过期提醒
+type Reminder struct {
+	HostId     string      `json:"host_id"`
+	Vendor     host.Vendor `json:"vendor"`
+	Region     string      `json:"region"`
+	InstanceId string      `json:"instance_id"`
+	Name       string      `json:"name"`
+	ExpireAt   int64       `json:"expire_at"`
+	Stage      Stage       `json:"stage"`
+	CreateAt   int64       `json:"create_at"`
+}
+
+// 提醒的标题
+func (r *Reminder) Subject() string {
+	if r.Stage == StageExpired {
+		return fmt.Sprintf("主机 %s 已经过期", r.Name)
+	}
+	return fmt.Sprintf("主机 %s 即将过期", r.Name)
+}
+
+// 提醒的正文
+func (r *Reminder) Text() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "主机: %s (%s)\n", r.Name, r.HostId)
+	fmt.Fprintf(b, "厂商: %s, 地域: %s, 实例: %s\n", r.Vendor, r.Region, r.InstanceId)
+	fmt.Fprintf(b, "过期时间: %s\n", time.UnixMilli(r.ExpireAt).Format("2006-01-02 15:04:05"))
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// 把提醒写入服务日志
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{
+		log: zap.L().Named("Reminder"),
+	}
+}
+
+type LogNotifier struct {
+	log logger.Logger
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, r *reminder.Reminder) error {
+	n.log.Warnf("%s, host: %s, instance: %s, expire at: %s", r.Subject(), r.HostId, r.InstanceId,
+		time.UnixMilli(r.ExpireAt).Format("2006-01-02 15:04:05"))
+	return nil
+}
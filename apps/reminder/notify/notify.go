@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+)
+
+// 根据配置创建提醒的发送方式
+func New() (reminder.Notifier, error) {
+	rc := conf.C().Reminder
+	switch rc.Notifier {
+	case conf.LogNotifier, "":
+		return NewLogNotifier(), nil
+	case conf.WebhookNotifier:
+		if rc.WebhookURL == "" {
+			return nil, fmt.Errorf("reminder webhook_url required")
+		}
+		return NewWebhookNotifier(rc.WebhookURL), nil
+	case conf.SMTPNotifier:
+		return NewSMTPNotifier(rc.SMTP), nil
+	default:
+		return nil, fmt.Errorf("unknown reminder notifier %s", rc.Notifier)
+	}
+}
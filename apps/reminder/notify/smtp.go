@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+)
+
+// 通过邮件发送提醒
+// 没有配置邮件服务器时, 邮件写入本地的mailbox目录, 用于本地开发和测试
+func NewSMTPNotifier(c *conf.SMTP) *SMTPNotifier {
+	return &SMTPNotifier{
+		conf: c,
+	}
+}
+
+type SMTPNotifier struct {
+	conf *conf.SMTP
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, r *reminder.Reminder) error {
+	if len(n.conf.To) == 0 {
+		return fmt.Errorf("smtp recipients required")
+	}
+	msg := n.message(r)
+
+	if n.conf.Host == "" {
+		return n.saveToMailbox(r, msg)
+	}
+
+	var auth smtp.Auth
+	if n.conf.Username != "" {
+		auth = smtp.PlainAuth("", n.conf.Username, n.conf.Password, n.conf.Host)
+	}
+	if err := smtp.SendMail(n.conf.Addr(), auth, n.conf.From, n.conf.To, msg); err != nil {
+		return fmt.Errorf("send mail error, %s", err)
+	}
+	return nil
+}
+
+// 邮件内容, 标题包含中文, 需要编码
+func (n *SMTPNotifier) message(r *reminder.Reminder) []byte {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "From: %s\r\n", n.conf.From)
+	fmt.Fprintf(b, "To: %s\r\n", strings.Join(n.conf.To, ", "))
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", r.Subject()))
+	fmt.Fprintf(b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(r.Text(), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// 本地的邮件替身, 每封邮件一个.eml文件
+func (n *SMTPNotifier) saveToMailbox(r *reminder.Reminder, msg []byte) error {
+	if err := os.MkdirAll(n.conf.MailboxDir, 0755); err != nil {
+		return fmt.Errorf("create mailbox dir error, %s", err)
+	}
+	name := fmt.Sprintf("%d-%s-%s.eml", r.CreateAt, r.HostId, r.Stage)
+	return os.WriteFile(filepath.Join(n.conf.MailboxDir, name), msg, 0644)
+}
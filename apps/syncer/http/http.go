@@ -0,0 +1,33 @@
+package http
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// Sync 模块的 HTTP API 服务实例
+var API = handler{}
+
+type handler struct {
+	sync syncer.Service
+	log  logger.Logger
+}
+
+func (h *handler) Init() {
+	h.log = zap.L().Named("SYNC API")
+
+	if apps.Sync == nil {
+		panic("dependence sync service is nil")
+	}
+	h.sync = apps.Sync
+}
+
+func (h *handler) Registry(r *router.Router) {
+	r.POST("/sync", permission.Required(policy.SyncRun, h.Sync))
+}
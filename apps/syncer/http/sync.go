@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// 手动触发同步, 同步完成后返回同步结果
+// 可以通过 vendor=ALI_CLOUD,TX_CLOUD 和 account=a1,a2 限制同步的范围
+func (h *handler) Sync(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := syncer.NewSyncRequest()
+	qs := r.URL.Query()
+	for _, item := range splitList(qs.Get("vendor")) {
+		v, err := host.ParseVendor(item)
+		if err != nil {
+			response.Failed(w, exception.NewBadRequest("vendor invalid, %s", err))
+			return
+		}
+		req.Vendor = append(req.Vendor, v)
+	}
+	req.Account = splitList(qs.Get("account"))
+
+	report, err := h.sync.Sync(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, report)
+}
+
+func splitList(v string) []string {
+	items := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
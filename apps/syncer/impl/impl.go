@@ -0,0 +1,91 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer/provider/file"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// 同步引擎, 依赖host服务, 需要在host服务之后初始化
+var Service *impl = &impl{}
+
+type impl struct {
+	log  logger.Logger
+	host host.Service
+
+	providers []syncer.Provider
+	// 同一时间只允许一个同步任务, 1表示正在同步
+	running int32
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("Sync")
+
+	if apps.Host == nil {
+		return fmt.Errorf("dependence host service is nil")
+	}
+	i.host = apps.Host
+
+	i.providers = []syncer.Provider{}
+	for _, pc := range conf.C().Sync.Providers {
+		if err := resolveAccount(pc); err != nil {
+			return err
+		}
+		p, err := newProvider(pc)
+		if err != nil {
+			return err
+		}
+		i.providers = append(i.providers, p)
+	}
+	return nil
+}
+
+// 通过account_id引用的账号, 以账号的厂商和Id作为同步的厂商和账号
+// 接入厂商SDK时从账号服务中读取AccessKey和Secret
+func resolveAccount(pc *conf.SyncProvider) error {
+	if pc.AccountId == "" {
+		return nil
+	}
+	if apps.Account == nil {
+		return fmt.Errorf("sync provider %s references an account, but account service is not available", pc.AccountId)
+	}
+
+	req := account.NewDescribeAccountRequest(pc.AccountId)
+	acc, err := apps.Account.DescribeAccount(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("sync provider account %s error, %s", pc.AccountId, err)
+	}
+	pc.Vendor = acc.Vendor.String()
+	pc.Account = acc.Id
+	return nil
+}
+
+// 根据配置创建Provider, 接入新的厂商SDK时在这里扩展
+func newProvider(pc *conf.SyncProvider) (syncer.Provider, error) {
+	vendor, err := host.ParseVendor(pc.Vendor)
+	if err != nil {
+		return nil, fmt.Errorf("sync provider %s vendor invalid, %s", pc.Account, err)
+	}
+	if pc.Account == "" {
+		return nil, fmt.Errorf("sync provider account required")
+	}
+	if pc.Namespace == "" {
+		pc.Namespace = host.DefaultNamespace
+	}
+
+	switch pc.Type {
+	case conf.FileProvider:
+		return file.NewProvider(vendor, pc.Account, pc.Namespace, pc.Path), nil
+	default:
+		return nil, fmt.Errorf("sync provider %s type %s not supported", pc.Account, pc.Type)
+	}
+}
@@ -0,0 +1,128 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+)
+
+const (
+	// 查询已同步主机时的分页大小
+	queryPageSize = 100
+)
+
+func (i *impl) Sync(ctx context.Context, req *syncer.SyncRequest) (*syncer.SyncReport, error) {
+	if !atomic.CompareAndSwapInt32(&i.running, 0, 1) {
+		return nil, exception.NewConflict("sync is running, please retry later")
+	}
+	defer atomic.StoreInt32(&i.running, 0)
+
+	report := syncer.NewSyncReport()
+	report.StartAt = ftime.Now().Timestamp()
+	for _, p := range i.providers {
+		if !req.Match(p) {
+			continue
+		}
+		item := i.syncProvider(ctx, p)
+		i.log.Infof("sync %s/%s done, total: %d, created: %d, updated: %d, unchanged: %d, vanished: %d, failed: %d",
+			item.Vendor, item.Account, item.Total, item.Created, item.Updated, item.Unchanged, item.Vanished, item.Failed)
+		report.Add(item)
+	}
+	report.EndAt = ftime.Now().Timestamp()
+	return report, nil
+}
+
+// 同步一个账号: 录入或者更新厂商返回的实例, 再标记厂商那边已经不存在的主机
+func (i *impl) syncProvider(ctx context.Context, p syncer.Provider) *syncer.ProviderReport {
+	report := syncer.NewProviderReport(p)
+
+	instances, err := p.ListInstances(ctx)
+	if err != nil {
+		// 拿不到完整的实例列表时, 不能判断哪些实例已经不存在
+		report.AddError(fmt.Errorf("list instances error, %s", err))
+		return report
+	}
+	report.Total = len(instances)
+
+	now := ftime.Now().Timestamp()
+	operator := "sync/" + p.Account()
+	seen := map[string]bool{}
+	for _, ins := range instances {
+		if ins == nil || ins.Resource == nil || ins.Describe == nil || ins.InstanceId == "" {
+			report.AddError(fmt.Errorf("instance data invalid, instance_id and describe required"))
+			continue
+		}
+		seen[ins.InstanceId] = true
+
+		req := host.NewUpsertHostRequest()
+		req.Resource = ins.Resource
+		req.Describe = ins.Describe
+		req.Vendor = p.Vendor()
+		req.SyncAccount = p.Account()
+		req.Namespace = p.Namespace()
+		req.SyncAt = now
+		req.UpdateBy = operator
+
+		// SyncAt 不参与Hash计算, 内容没有变化时只刷新SyncAt, 不产生新版本
+		resp, err := i.host.UpsertHost(ctx, req)
+		switch {
+		case err != nil:
+			report.AddError(fmt.Errorf("sync instance %s error, %s", ins.InstanceId, err))
+		case resp.Created:
+			report.Created++
+		case resp.Unchanged:
+			report.Unchanged++
+		default:
+			report.Updated++
+		}
+	}
+
+	i.markVanished(ctx, p, seen, operator, report)
+	return report
+}
+
+// 该账号下已同步过, 但本次厂商没有返回的主机, 标记为VANISHED, SyncAt保留最后一次看到的时间
+func (i *impl) markVanished(ctx context.Context, p syncer.Provider, seen map[string]bool, operator string, report *syncer.ProviderReport) {
+	query := host.NewQueryHostRequest()
+	query.Vendor = []host.Vendor{p.Vendor()}
+	query.SyncAccount = []string{p.Account()}
+	query.PageSize = queryPageSize
+	query.UseCursor = true
+	query.WithTotal = false
+
+	vanished := []*host.Host{}
+	for {
+		set, err := i.host.QueryHost(ctx, query)
+		if err != nil {
+			report.AddError(fmt.Errorf("query synced hosts error, %s", err))
+			return
+		}
+		for _, ins := range set.Items {
+			if ins.InstanceId != "" && !seen[ins.InstanceId] && ins.Status != syncer.StatusVanished {
+				vanished = append(vanished, ins)
+			}
+		}
+		if set.NextCursor == "" {
+			break
+		}
+		query.Cursor = host.NewCursor(set.Items[len(set.Items)-1])
+	}
+
+	for _, ins := range vanished {
+		patch := host.NewPatchUpdateHostRequest()
+		patch.Id = ins.Id
+		patch.Status = syncer.StatusVanished
+		patch.UpdateBy = operator
+		if _, err := i.host.UpdateHost(ctx, patch); err != nil {
+			report.AddError(fmt.Errorf("mark host %s vanished error, %s", ins.Id, err))
+			continue
+		}
+		report.Vanished++
+	}
+}
@@ -0,0 +1,83 @@
+package impl_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	twoInstances = `[
+  {"instance_id": "ins-01", "region": "hangzhou", "type": "sm1", "name": "host01", "status": "Running", "cpu": 1, "memory": 2048},
+  {"instance_id": "ins-02", "region": "hangzhou", "type": "sm1", "name": "host02", "status": "Running", "cpu": 2, "memory": 4096}
+]`
+	oneInstance = `[
+  {"instance_id": "ins-01", "region": "hangzhou", "type": "sm1", "name": "host01", "status": "Stopped", "cpu": 1, "memory": 2048}
+]`
+)
+
+func TestSync(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "ali.json")
+	should.NoError(os.WriteFile(path, []byte(twoInstances), 0644))
+
+	cfg := conf.NewDefaultConfig()
+	cfg.Sync.Providers = []*conf.SyncProvider{
+		{Vendor: "ALI_CLOUD", Account: "ali-dev", Type: conf.FileProvider, Path: path},
+	}
+	conf.SetGlobalConfig(cfg)
+	should.NoError(memory.Service.Init())
+	apps.Host = memory.Service
+	if !should.NoError(impl.Service.Init()) {
+		return
+	}
+
+	report, err := impl.Service.Sync(ctx, syncer.NewSyncRequest())
+	if should.NoError(err) && should.Len(report.Items, 1) {
+		should.Equal(2, report.Items[0].Created)
+	}
+
+	// ins-01 状态变化, ins-02 已经不存在
+	should.NoError(os.WriteFile(path, []byte(oneInstance), 0644))
+	report, err = impl.Service.Sync(ctx, syncer.NewSyncRequest())
+	if should.NoError(err) && should.Len(report.Items, 1) {
+		should.Equal(1, report.Items[0].Updated)
+		should.Equal(1, report.Items[0].Vanished)
+	}
+
+	// 再次同步, 没有变化, 只刷新同步时间
+	before, err := memory.Service.DesribeHost(ctx, host.NewDescribeHostRequestWithInstance(host.ALI_CLOUD, "ins-01"))
+	should.NoError(err)
+	time.Sleep(2 * time.Millisecond)
+	report, err = impl.Service.Sync(ctx, syncer.NewSyncRequest())
+	if should.NoError(err) && should.Len(report.Items, 1) {
+		should.Equal(1, report.Items[0].Unchanged)
+		should.Equal(0, report.Items[0].Vanished)
+	}
+	after, err := memory.Service.DesribeHost(ctx, host.NewDescribeHostRequestWithInstance(host.ALI_CLOUD, "ins-01"))
+	if should.NoError(err) {
+		should.Greater(after.SyncAt, before.SyncAt)
+		should.Equal(before.Version, after.Version)
+	}
+
+	ins, err := memory.Service.DesribeHost(ctx, host.NewDescribeHostRequestWithInstance(host.ALI_CLOUD, "ins-02"))
+	if should.NoError(err) {
+		should.Equal(syncer.StatusVanished, ins.Status)
+		should.Equal("ali-dev", ins.SyncAccount)
+		should.NotZero(ins.SyncAt)
+		should.Less(ins.SyncAt, after.SyncAt)
+	}
+}
@@ -0,0 +1,61 @@
+package syncer
+
+import (
+	"context"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+type Service interface {
+	// 从云厂商同步主机信息
+	Sync(context.Context, *SyncRequest) (*SyncReport, error)
+}
+
+// 云厂商实例数据的来源, 每个厂商账号对应一个Provider
+type Provider interface {
+	// 所属厂商
+	Vendor() host.Vendor
+	// 账号名称, 同步的主机会记录到SyncAccount
+	Account() string
+	// 同步的主机归属的命名空间
+	Namespace() string
+	// 列出账号下的所有实例, 实例必须有InstanceId
+	ListInstances(context.Context) ([]*host.Host, error)
+}
+
+func NewSyncRequest() *SyncRequest {
+	return &SyncRequest{}
+}
+
+type SyncRequest struct {
+	// 只同步这些厂商的账号, 为空表示不限制
+	Vendor []host.Vendor
+	// 只同步这些账号, 为空表示不限制
+	Account []string
+}
+
+// Provider 是否需要同步
+func (req *SyncRequest) Match(p Provider) bool {
+	if len(req.Vendor) > 0 {
+		found := false
+		for _, v := range req.Vendor {
+			if v == p.Vendor() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(req.Account) > 0 {
+		for _, a := range req.Account {
+			if a == p.Account() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package syncer
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+)
+
+const (
+	// 厂商那边已经不存在的实例, 同步时把主机状态修改为该值
+	StatusVanished = "VANISHED"
+)
+
+func NewSyncReport() *SyncReport {
+	return &SyncReport{
+		Items: []*ProviderReport{},
+	}
+}
+
+// 一次同步的结果, 每个账号一条
+type SyncReport struct {
+	StartAt int64             `json:"start_at"`
+	EndAt   int64             `json:"end_at"`
+	Items   []*ProviderReport `json:"items"`
+}
+
+func (r *SyncReport) Add(item *ProviderReport) {
+	r.Items = append(r.Items, item)
+}
+
+// 同步失败的总数
+func (r *SyncReport) Failed() int {
+	n := 0
+	for _, item := range r.Items {
+		n += item.Failed
+	}
+	return n
+}
+
+func NewProviderReport(p Provider) *ProviderReport {
+	return &ProviderReport{
+		Vendor:  p.Vendor(),
+		Account: p.Account(),
+	}
+}
+
+type ProviderReport struct {
+	Vendor  host.Vendor `json:"vendor"`
+	Account string      `json:"account"`
+	// 厂商返回的实例数量
+	Total int `json:"total"`
+	// 新录入的主机
+	Created int `json:"created"`
+	// 内容有变化, 更新的主机
+	Updated int `json:"updated"`
+	// 内容没有变化, 没有写入的主机
+	Unchanged int `json:"unchanged"`
+	// 厂商那边已经不存在, 标记为VANISHED的主机
+	Vanished int `json:"vanished"`
+	// 失败的数量, 失败原因记录在Errors中
+	Failed int      `json:"failed"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func (r *ProviderReport) AddError(err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, err.Error())
+}
@@ -0,0 +1,56 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+)
+
+// 从本地JSON文件读取实例数据的Provider, 用于离线开发和测试
+// 文件内容是主机对象的数组, 每次同步都重新读取, 修改文件即可模拟厂商数据的变化
+func NewProvider(vendor host.Vendor, account, namespace, path string) *Provider {
+	return &Provider{
+		vendor:    vendor,
+		account:   account,
+		namespace: namespace,
+		path:      path,
+	}
+}
+
+type Provider struct {
+	vendor    host.Vendor
+	account   string
+	namespace string
+	path      string
+}
+
+var _ syncer.Provider = (*Provider)(nil)
+
+func (p *Provider) Vendor() host.Vendor {
+	return p.vendor
+}
+
+func (p *Provider) Account() string {
+	return p.account
+}
+
+func (p *Provider) Namespace() string {
+	return p.namespace
+}
+
+func (p *Provider) ListInstances(ctx context.Context) ([]*host.Host, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("read instance file %s error, %s", p.path, err)
+	}
+
+	instances := []*host.Host{}
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, fmt.Errorf("decode instance file %s error, %s", p.path, err)
+	}
+	return instances, nil
+}
@@ -0,0 +1,16 @@
+package user
+
+import "context"
+
+type contextKey struct{}
+
+// 认证通过后把用户放入请求的上下文
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// 从请求上下文中读取当前用户, 未认证时返回nil
+func FromContext(ctx context.Context) *User {
+	u, _ := ctx.Value(contextKey{}).(*User)
+	return u
+}
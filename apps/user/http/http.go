@@ -0,0 +1,45 @@
+package http
+
+import (
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/permission"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+// User 模块的 HTTP API 服务实例
+var API = handler{}
+
+type handler struct {
+	user   user.Service
+	policy policy.Service
+	log    logger.Logger
+}
+
+func (h *handler) Init() {
+	h.log = zap.L().Named("USER API")
+
+	if apps.User == nil {
+		panic("dependence user service is nil")
+	}
+	h.user = apps.User
+
+	if apps.Policy == nil {
+		panic("dependence policy service is nil")
+	}
+	h.policy = apps.Policy
+}
+
+func (h *handler) Registry(r *router.Router) {
+	// 登录不需要认证
+	r.POST("/login", h.Login)
+	r.POST("/logout", h.Logout)
+	r.POST("/users", permission.Required(policy.UserWrite, h.CreateUser))
+	r.PUT("/users/:username/role", permission.Required(policy.UserWrite, h.UpdateUserRole))
+	// 查询各角色的权限
+	r.GET("/policies", h.QueryPolicy)
+}
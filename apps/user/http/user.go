@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/infraboard/mcube/http/request"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// 用户名密码登录, 返回令牌
+func (h *handler) Login(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := user.NewLoginRequest()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	tk, err := h.user.Login(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, tk)
+}
+
+// 使当前请求携带的令牌失效
+func (h *handler) Logout(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.LogoutRequest{AccessToken: user.GetTokenFromHTTP(r)}
+	if err := h.user.Logout(r.Context(), req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, "ok")
+}
+
+func (h *handler) CreateUser(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := user.NewCreateUserRequest()
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.user.CreateUser(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+// 修改用户的角色, 只有管理员可以操作
+func (h *handler) UpdateUserRole(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req := user.NewUpdateUserRoleRequest(ps.ByName("username"))
+	if err := request.GetDataFromRequest(r, req); err != nil {
+		response.Failed(w, err)
+		return
+	}
+
+	ins, err := h.user.UpdateUserRole(r.Context(), req)
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, ins)
+}
+
+func (h *handler) QueryPolicy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	set, err := h.policy.QueryPolicy(r.Context())
+	if err != nil {
+		response.Failed(w, err)
+		return
+	}
+	response.Success(w, set)
+}
@@ -0,0 +1,81 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/jwt"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/logger"
+	"github.com/infraboard/mcube/logger/zap"
+)
+
+var Service *impl = &impl{}
+
+type impl struct {
+	log   logger.Logger
+	store user.Store
+	// 令牌有效期
+	ttl time.Duration
+
+	// 开启JWT认证时使用
+	jwtConf  *conf.JWTAuth
+	verifier *jwt.Verifier
+}
+
+func (i *impl) Init() error {
+	i.log = zap.L().Named("User")
+	i.ttl = time.Duration(conf.C().Auth.TokenTTL) * time.Second
+	i.jwtConf = conf.C().Auth.JWT
+
+	// 用户和主机数据使用相同的存储
+	switch conf.C().App.Storage {
+	case conf.MySQLStorage, "":
+		db, err := conf.C().MySQL.GetDB()
+		if err != nil {
+			return err
+		}
+		i.store = newMySQLStore(db)
+	case conf.MemoryStorage:
+		i.store = newMemoryStore()
+	default:
+		return fmt.Errorf("unknown storage type %s", conf.C().App.Storage)
+	}
+
+	if err := i.initJWT(context.Background()); err != nil {
+		return err
+	}
+	return i.bootstrap(context.Background())
+}
+
+// 配置了初始用户时, 用户不存在则创建, 初始用户总是管理员
+func (i *impl) bootstrap(ctx context.Context) error {
+	ac := conf.C().Auth
+	if ac.BootstrapUser == "" {
+		return nil
+	}
+
+	u, err := i.store.GetUser(ctx, user.NewDescribeUserRequestWithName(ac.BootstrapUser))
+	if err == nil {
+		if u.Role == user.RoleAdmin {
+			return nil
+		}
+		_, err = i.UpdateUserRole(ctx, &user.UpdateUserRoleRequest{Username: u.Username, Role: user.RoleAdmin})
+		return err
+	}
+	if !exception.IsNotFoundError(err) {
+		return err
+	}
+
+	req := user.NewCreateUserRequest()
+	req.Username, req.Password, req.Role = ac.BootstrapUser, ac.BootstrapPassword, user.RoleAdmin
+	if _, err := i.CreateUser(ctx, req); err != nil {
+		return fmt.Errorf("create bootstrap user error, %s", err)
+	}
+	i.log.Infof("bootstrap user %s created", ac.BootstrapUser)
+	return nil
+}
@@ -0,0 +1,91 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/jwt"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 外部身份提供方的用户Id前缀, 和本地用户区分
+const jwtUserPrefix = "jwt:"
+
+// 根据配置初始化JWT校验, 启动时先加载一次JWKS, 尽早发现配置错误
+func (i *impl) initJWT(ctx context.Context) error {
+	jc := i.jwtConf
+	if !jc.Enable {
+		return nil
+	}
+	if jc.JWKS == "" {
+		return fmt.Errorf("auth.jwt.jwks required when jwt enabled")
+	}
+	for claim, role := range jc.RoleMapping {
+		if _, err := user.ParseRole(role); err != nil {
+			return fmt.Errorf("auth.jwt.role_mapping %s error, %s", claim, err)
+		}
+	}
+	if jc.DefaultRole != "" {
+		if _, err := user.ParseRole(jc.DefaultRole); err != nil {
+			return fmt.Errorf("auth.jwt.default_role error, %s", err)
+		}
+	}
+
+	keys := jwt.NewKeySet(jc.JWKS, time.Duration(jc.RefreshInterval)*time.Second)
+	if err := keys.Refresh(ctx); err != nil {
+		return err
+	}
+	i.verifier = jwt.NewVerifier(keys)
+	i.verifier.Issuer = jc.Issuer
+	i.verifier.Audience = jc.Audience
+	i.verifier.Leeway = time.Duration(jc.Leeway) * time.Second
+	return nil
+}
+
+// 校验JWT, 并把声明映射为用户, 外部用户不保存到本地
+func (i *impl) validateJWT(ctx context.Context, token string) (*user.User, error) {
+	claims, err := i.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, exception.NewUnauthorized("jwt invalid, %s", err)
+	}
+
+	sub := claims.String("sub")
+	if sub == "" {
+		return nil, exception.NewUnauthorized("jwt sub claim required")
+	}
+	username := claims.String(i.jwtConf.UsernameClaim)
+	if username == "" {
+		username = sub
+	}
+
+	role := i.roleFromClaims(claims)
+	if role == "" {
+		return nil, exception.NewPermissionDeny("user %s has no role", username)
+	}
+	return &user.User{
+		Id:       jwtUserPrefix + sub,
+		Username: username,
+		Role:     role,
+	}, nil
+}
+
+func (i *impl) roleFromClaims(claims jwt.Claims) user.Role {
+	jc := i.jwtConf
+
+	roles := []user.Role{}
+	for _, v := range claims.Strings(jc.RoleClaim) {
+		if len(jc.RoleMapping) > 0 {
+			v = jc.RoleMapping[v]
+		}
+		if r, err := user.ParseRole(v); err == nil {
+			roles = append(roles, r)
+		}
+	}
+	if r := user.HighestRole(roles); r != "" {
+		return r
+	}
+	return user.Role(jc.DefaultRole)
+}
@@ -0,0 +1,196 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/sqlbuilder"
+)
+
+const (
+	// MySQL 唯一索引冲突的错误码
+	errDuplicateEntry = 1062
+
+	insertUserSQL     = `INSERT INTO user (id, username, password, role, create_at, update_at) VALUES (?,?,?,?,?,?)`
+	queryUserSQL      = `SELECT id, username, password, role, create_at, update_at FROM user`
+	updateUserRoleSQL = `UPDATE user SET role=?, update_at=? WHERE id=?`
+
+	insertTokenSQL = `INSERT INTO user_token (token_key, user_id, username, create_at, expire_at) VALUES (?,?,?,?,?)`
+	queryTokenSQL  = `SELECT user_id, username, create_at, expire_at FROM user_token WHERE token_key=?`
+	deleteTokenSQL = `DELETE FROM user_token WHERE token_key=?`
+	purgeTokenSQL  = `DELETE FROM user_token WHERE expire_at<?`
+)
+
+func newMySQLStore(db *sql.DB) *mysqlStore {
+	return &mysqlStore{db: db}
+}
+
+type mysqlStore struct {
+	db *sql.DB
+}
+
+func (s *mysqlStore) SaveUser(ctx context.Context, u *user.User) error {
+	_, err := s.db.ExecContext(ctx, insertUserSQL, u.Id, u.Username, u.Password, u.Role, u.CreateAt, u.UpdateAt)
+	if err != nil {
+		var e *mysql.MySQLError
+		if errors.As(err, &e) && e.Number == errDuplicateEntry {
+			return exception.NewConflict("user %s already exists", u.Username)
+		}
+		return fmt.Errorf("save user error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) GetUser(ctx context.Context, req *user.DescribeUserRequest) (*user.User, error) {
+	query := sqlbuilder.NewQuery(queryUserSQL)
+	if req.Id != "" {
+		query.Where("id = ?", req.Id)
+	} else {
+		query.Where("username = ?", req.Username)
+	}
+	sqlStr, args := query.BuildQuery()
+
+	u := &user.User{}
+	err := s.db.QueryRowContext(ctx, sqlStr, args...).Scan(&u.Id, &u.Username, &u.Password, &u.Role, &u.CreateAt, &u.UpdateAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewNotFound("user %s%s not found", req.Id, req.Username)
+		}
+		return nil, fmt.Errorf("query user error, %s", err)
+	}
+	return u, nil
+}
+
+func (s *mysqlStore) UpdateUserRole(ctx context.Context, id string, role user.Role, updateAt int64) error {
+	if _, err := s.db.ExecContext(ctx, updateUserRoleSQL, role, updateAt, id); err != nil {
+		return fmt.Errorf("update user role error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) SaveToken(ctx context.Context, key string, t *user.Token) error {
+	_, err := s.db.ExecContext(ctx, insertTokenSQL, key, t.UserId, t.Username, t.CreateAt, t.ExpireAt)
+	if err != nil {
+		return fmt.Errorf("save token error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) GetToken(ctx context.Context, key string) (*user.Token, error) {
+	t := &user.Token{}
+	err := s.db.QueryRowContext(ctx, queryTokenSQL, key).Scan(&t.UserId, &t.Username, &t.CreateAt, &t.ExpireAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewNotFound("token not found")
+		}
+		return nil, fmt.Errorf("query token error, %s", err)
+	}
+	return t, nil
+}
+
+func (s *mysqlStore) DeleteToken(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, deleteTokenSQL, key); err != nil {
+		return fmt.Errorf("delete token error, %s", err)
+	}
+	return nil
+}
+
+func (s *mysqlStore) PurgeToken(ctx context.Context, expireBefore int64) error {
+	if _, err := s.db.ExecContext(ctx, purgeTokenSQL, expireBefore); err != nil {
+		return fmt.Errorf("purge token error, %s", err)
+	}
+	return nil
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		users:  map[string]*user.User{},
+		tokens: map[string]*user.Token{},
+	}
+}
+
+// 基于内存的存储, 配合内存存储的host服务使用, 服务重启后需要重新创建用户
+type memoryStore struct {
+	lock   sync.Mutex
+	users  map[string]*user.User
+	tokens map[string]*user.Token
+}
+
+func (s *memoryStore) SaveUser(ctx context.Context, u *user.User) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, item := range s.users {
+		if item.Username == u.Username {
+			return exception.NewConflict("user %s already exists", u.Username)
+		}
+	}
+	cp := *u
+	s.users[u.Id] = &cp
+	return nil
+}
+
+func (s *memoryStore) GetUser(ctx context.Context, req *user.DescribeUserRequest) (*user.User, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, item := range s.users {
+		if (req.Id != "" && item.Id == req.Id) || (req.Id == "" && item.Username == req.Username) {
+			cp := *item
+			return &cp, nil
+		}
+	}
+	return nil, exception.NewNotFound("user %s%s not found", req.Id, req.Username)
+}
+
+func (s *memoryStore) UpdateUserRole(ctx context.Context, id string, role user.Role, updateAt int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if u, ok := s.users[id]; ok {
+		u.Role, u.UpdateAt = role, updateAt
+	}
+	return nil
+}
+
+func (s *memoryStore) SaveToken(ctx context.Context, key string, t *user.Token) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cp := *t
+	cp.AccessToken = ""
+	s.tokens[key] = &cp
+	return nil
+}
+
+func (s *memoryStore) GetToken(ctx context.Context, key string) (*user.Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t, ok := s.tokens[key]
+	if !ok {
+		return nil, exception.NewNotFound("token not found")
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (s *memoryStore) DeleteToken(ctx context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+func (s *memoryStore) PurgeToken(ctx context.Context, expireBefore int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for k, t := range s.tokens {
+		if t.ExpireAt < expireBefore {
+			delete(s.tokens, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package impl
+
+import (
+	"context"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/jwt"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/types/ftime"
+	"github.com/rs/xid"
+)
+
+// 和SetPassword相同cost的固定哈希, 只用于用户不存在时的比较
+var dummyUser = &user.User{
+	Password: "$2a$10$IPnFZGrpacg0Kkd7cyan0OhUI//FrsnOLHXIrkxjC4lTWNSx8W.Fm",
+}
+
+func (i *impl) CreateUser(ctx context.Context, req *user.CreateUserRequest) (*user.User, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate create user request error, %s", err)
+	}
+
+	ins := &user.User{
+		Id:       xid.New().String(),
+		Username: req.Username,
+		Role:     req.Role,
+		CreateAt: ftime.Now().Timestamp(),
+	}
+	if err := ins.SetPassword(req.Password); err != nil {
+		return nil, err
+	}
+	if err := i.store.SaveUser(ctx, ins); err != nil {
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (i *impl) DescribeUser(ctx context.Context, req *user.DescribeUserRequest) (*user.User, error) {
+	return i.store.GetUser(ctx, req)
+}
+
+func (i *impl) Login(ctx context.Context, req *user.LoginRequest) (*user.Token, error) {
+	if err := req.Validate(); err != nil {
+		return nil, exception.NewBadRequest("validate login request error, %s", err)
+	}
+
+	// 用户不存在和密码错误返回相同的错误, 避免探测用户名
+	u, err := i.store.GetUser(ctx, user.NewDescribeUserRequestWithName(req.Username))
+	if err != nil && !exception.IsNotFoundError(err) {
+		return nil, err
+	}
+	if u == nil {
+		// 用户不存在时也做一次bcrypt比较, 避免通过响应时间探测用户名
+		dummyUser.CheckPassword(req.Password)
+		return nil, exception.NewUnauthorized("username or password is wrong")
+	}
+	if !u.CheckPassword(req.Password) {
+		return nil, exception.NewUnauthorized("username or password is wrong")
+	}
+
+	now := time.Now()
+	tk, err := user.NewToken(u, now.UnixNano()/1000000, now.Add(i.ttl).UnixNano()/1000000)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.store.SaveToken(ctx, user.TokenKey(tk.AccessToken), tk); err != nil {
+		return nil, err
+	}
+
+	// 顺便清理已经过期的令牌
+	if err := i.store.PurgeToken(ctx, tk.CreateAt); err != nil {
+		i.log.Warnf("purge expired token error, %s", err)
+	}
+	return tk, nil
+}
+
+func (i *impl) Logout(ctx context.Context, req *user.LogoutRequest) error {
+	return i.store.DeleteToken(ctx, user.TokenKey(req.AccessToken))
+}
+
+func (i *impl) ValidateToken(ctx context.Context, req *user.ValidateTokenRequest) (*user.User, error) {
+	if req.AccessToken == "" {
+		return nil, exception.NewUnauthorized("access token required")
+	}
+	// 本地令牌不包含 ., 按格式区分
+	if i.verifier != nil && jwt.IsJWT(req.AccessToken) {
+		return i.validateJWT(ctx, req.AccessToken)
+	}
+
+	tk, err := i.store.GetToken(ctx, user.TokenKey(req.AccessToken))
+	if err != nil {
+		if exception.IsNotFoundError(err) {
+			return nil, exception.NewUnauthorized("access token invalid")
+		}
+		return nil, err
+	}
+	if tk.ExpireAt <= ftime.Now().Timestamp() {
+		return nil, exception.NewUnauthorized("access token expired")
+	}
+
+	u, err := i.store.GetUser(ctx, &user.DescribeUserRequest{Id: tk.UserId})
+	if err != nil {
+		if exception.IsNotFoundError(err) {
+			return nil, exception.NewUnauthorized("user of the access token not exists")
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+func (i *impl) UpdateUserRole(ctx context.Context, req *user.UpdateUserRoleRequest) (*user.User, error) {
+	if _, err := user.ParseRole(string(req.Role)); err != nil {
+		return nil, exception.NewBadRequest("%s", err)
+	}
+
+	u, err := i.store.GetUser(ctx, user.NewDescribeUserRequestWithName(req.Username))
+	if err != nil {
+		return nil, err
+	}
+	u.Role, u.UpdateAt = req.Role, ftime.Now().Timestamp()
+	if err := i.store.UpdateUserRole(ctx, u.Id, u.Role, u.UpdateAt); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,44 @@
+package impl_test
+
+import (
+	"context"
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	cfg := conf.NewDefaultConfig()
+	cfg.App.Storage = conf.MemoryStorage
+	cfg.Auth.BootstrapUser = "admin"
+	cfg.Auth.BootstrapPassword = "admin@123"
+	conf.SetGlobalConfig(cfg)
+	if !should.NoError(impl.Service.Init()) {
+		return
+	}
+
+	_, err := impl.Service.Login(ctx, &user.LoginRequest{Username: "admin", Password: "wrong password"})
+	should.Equal(exception.Unauthorized, err.(exception.APIException).ErrorCode())
+
+	tk, err := impl.Service.Login(ctx, &user.LoginRequest{Username: "admin", Password: "admin@123"})
+	if !should.NoError(err) {
+		return
+	}
+	u, err := impl.Service.ValidateToken(ctx, &user.ValidateTokenRequest{AccessToken: tk.AccessToken})
+	if should.NoError(err) {
+		should.Equal("admin", u.Username)
+		should.Equal(user.RoleAdmin, u.Role)
+	}
+
+	should.NoError(impl.Service.Logout(ctx, &user.LogoutRequest{AccessToken: tk.AccessToken}))
+	_, err = impl.Service.ValidateToken(ctx, &user.ValidateTokenRequest{AccessToken: tk.AccessToken})
+	should.Error(err)
+}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"context"
+	"fmt"
+)
+
+// 本地用户和登录令牌
+type Service interface {
+	CreateUser(context.Context, *CreateUserRequest) (*User, error)
+	DescribeUser(context.Context, *DescribeUserRequest) (*User, error)
+	// 校验用户名密码, 颁发有过期时间的令牌
+	Login(context.Context, *LoginRequest) (*Token, error)
+	// 令牌立即失效
+	Logout(context.Context, *LogoutRequest) error
+	// 校验令牌, 返回令牌所属的用户, 令牌无效或者过期时返回401异常
+	ValidateToken(context.Context, *ValidateTokenRequest) (*User, error)
+	// 修改用户的角色
+	UpdateUserRole(context.Context, *UpdateUserRoleRequest) (*User, error)
+}
+
+func NewCreateUserRequest() *CreateUserRequest {
+	return &CreateUserRequest{
+		Role: RoleViewer,
+	}
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	// 默认为只读的viewer
+	Role Role `json:"role"`
+}
+
+func (req *CreateUserRequest) Validate() error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	if _, err := ParseRole(string(req.Role)); err != nil {
+		return err
+	}
+	if len(req.Password) < MinPasswordLength {
+		return fmt.Errorf("password length must be at least %d", MinPasswordLength)
+	}
+	return nil
+}
+
+// 按Id或者用户名查询, Id优先
+type DescribeUserRequest struct {
+	Id       string
+	Username string
+}
+
+func NewDescribeUserRequestWithName(username string) *DescribeUserRequest {
+	return &DescribeUserRequest{Username: username}
+}
+
+func NewLoginRequest() *LoginRequest {
+	return &LoginRequest{}
+}
+
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (req *LoginRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+type LogoutRequest struct {
+	AccessToken string
+}
+
+type ValidateTokenRequest struct {
+	AccessToken string
+}
+
+func NewUpdateUserRoleRequest(username string) *UpdateUserRoleRequest {
+	return &UpdateUserRoleRequest{Username: username}
+}
+
+type UpdateUserRoleRequest struct {
+	Username string `json:"-"`
+	Role     Role   `json:"role"`
+}
+
+// 用户和令牌的存储
+type Store interface {
+	SaveUser(context.Context, *User) error
+	// 修改用户的角色
+	UpdateUserRole(ctx context.Context, id string, role Role, updateAt int64) error
+	// 用户不存在时返回NotFound异常
+	GetUser(context.Context, *DescribeUserRequest) (*User, error)
+	// 保存令牌, key为令牌的摘要, 不保存令牌明文
+	SaveToken(ctx context.Context, key string, t *Token) error
+	// 令牌不存在时返回NotFound异常
+	GetToken(ctx context.Context, key string) (*Token, error)
+	DeleteToken(ctx context.Context, key string) error
+	// 清除在该时间之前过期的令牌
+	PurgeToken(ctx context.Context, expireBefore int64) error
+}
@@ -0,0 +1,75 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	validate = validator.New()
+)
+
+const (
+	MinPasswordLength = 8
+)
+
+type User struct {
+	Id       string `json:"id"`
+	Username string `json:"username"`
+	// bcrypt 哈希后的密码, 不对外返回
+	Password string `json:"-"`
+	Role     Role   `json:"role"`
+	CreateAt int64  `json:"create_at"`
+	UpdateAt int64  `json:"update_at"`
+}
+
+// 使用bcrypt保存密码
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.Password = string(hash)
+	return nil
+}
+
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+}
+
+// 登录颁发的令牌, 通过 Authorization: Bearer <access_token> 使用
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	UserId      string `json:"user_id"`
+	Username    string `json:"username"`
+	CreateAt    int64  `json:"create_at"`
+	ExpireAt    int64  `json:"expire_at"`
+}
+
+// 生成随机令牌
+func NewToken(u *User, createAt, expireAt int64) (*Token, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return &Token{
+		AccessToken: base64.RawURLEncoding.EncodeToString(b),
+		TokenType:   "Bearer",
+		UserId:      u.Id,
+		Username:    u.Username,
+		CreateAt:    createAt,
+		ExpireAt:    expireAt,
+	}, nil
+}
+
+// 令牌的摘要, 存储和查询都使用摘要, 数据库泄露时令牌不可用
+func TokenKey(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,15 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+)
+
+// 从 Authorization: Bearer <access_token> 中读取令牌
+func GetTokenFromHTTP(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package user
+
+import "fmt"
+
+type Role string
+
+const (
+	// 只读
+	RoleViewer = Role("viewer")
+	// 可以录入和修改主机
+	RoleOperator = Role("operator")
+	// 所有权限, 包括删除主机和管理用户
+	RoleAdmin = Role("admin")
+)
+
+var (
+	roles = map[Role]bool{
+		RoleViewer:   true,
+		RoleOperator: true,
+		RoleAdmin:    true,
+	}
+)
+
+func ParseRole(s string) (Role, error) {
+	if r := Role(s); roles[r] {
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %s, supported: viewer, operator, admin", s)
+}
+
+// 多个角色中权限最大的角色, 没有角色时返回空
+func HighestRole(roles []Role) Role {
+	for _, r := range []Role{RoleAdmin, RoleOperator, RoleViewer} {
+		for _, item := range roles {
+			if item == r {
+				return r
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/importer"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importReq      = importer.NewImportRequest()
+	importMode     string
+	importMappings []string
+)
+
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "主机管理",
+	Long:  `主机管理, 支持从CSV文件导入主机`,
+}
+
+var hostImportCmd = &cobra.Command{
+	Use:   "import <file.csv>",
+	Short: "从CSV文件导入主机",
+	Long:  `从CSV文件导入主机, 第一行为表头, 列名和主机的字段名相同, 不同时通过 --map 指定`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		var err error
+		importReq.Mode = importer.Mode(importMode)
+		if importReq.Mapping, err = importer.ParseMapping(importMappings); err != nil {
+			return err
+		}
+
+		if err := loadGlobalConfig(configType); err != nil {
+			return err
+		}
+		if err := loadGlobalLogger(); err != nil {
+			return err
+		}
+		if err := loadHostService(); err != nil {
+			return err
+		}
+		if err := loadNamespaceService(); err != nil {
+			return err
+		}
+		// 命令行导入不限制命名空间, 只检查是否存在
+		importReq.CheckNamespace = func(ctx context.Context, ns string) error {
+			_, err := apps.Namespace.DescribeNamespace(ctx, namespace.NewDescribeNamespaceRequest(ns))
+			if exception.IsNotFoundError(err) {
+				return fmt.Errorf("namespace %s not exists", ns)
+			}
+			return err
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		report, err := importer.Import(context.Background(), apps.Host, f, importReq)
+		if err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+
+		if report.Failed > 0 {
+			return fmt.Errorf("import finished with %d failed lines", report.Failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	hostCmd.PersistentFlags().StringVarP(&configType, "config_type", "t", "file", "the restful-api demo config type")
+	hostCmd.PersistentFlags().StringVarP(&confFile, "config_file", "f", "etc/restful-api.toml", "the restful-api config file path")
+	hostImportCmd.Flags().StringVar(&importMode, "mode", string(importer.CreateMode), "import mode, create: always create new hosts, upsert: create or update by vendor and instance_id")
+	hostImportCmd.Flags().BoolVar(&importReq.DryRun, "dry_run", false, "only validate the file and report line errors, do not write")
+	hostImportCmd.Flags().IntVar(&importReq.BatchSize, "batch_size", importer.DefaultBatchSize, "the number of lines written in a batch")
+	hostImportCmd.Flags().StringArrayVar(&importMappings, "map", nil, "map a csv column to a host field, e.g. --map 'IP Address=private_ip', use - as field to ignore the column")
+	hostImportCmd.Flags().StringVar(&importReq.Operator, "operator", "cli", "the operator recorded in host revisions")
+	hostImportCmd.Flags().StringVar(&importReq.Namespace, "namespace", importReq.Namespace, "the namespace of the lines without a namespace column")
+
+	hostCmd.AddCommand(hostImportCmd)
+	RootCmd.AddCommand(hostCmd)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/migrate"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateTarget int
+	migrateSteps  int
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "数据库结构迁移",
+	Long:  `数据库结构迁移, 支持 status/up/down`,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看迁移脚本的执行状态",
+	RunE: func(c *cobra.Command, args []string) error {
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+
+		status, err := m.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = time.UnixMilli(s.AppliedAt).Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	},
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "执行未执行的迁移脚本",
+	RunE: func(c *cobra.Command, args []string) error {
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+
+		done, err := m.Up(context.Background(), migrateTarget)
+		for _, mg := range done {
+			fmt.Printf("migrate up %04d_%s ok\n", mg.Version, mg.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "回滚已执行的迁移脚本",
+	RunE: func(c *cobra.Command, args []string) error {
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+
+		done, err := m.Down(context.Background(), migrateSteps)
+		for _, mg := range done {
+			fmt.Printf("migrate down %04d_%s ok\n", mg.Version, mg.Name)
+		}
+		return err
+	},
+}
+
+// 迁移只需要配置和数据库连接, 不需要启动服务
+func newMigrator() (*migrate.Migrator, error) {
+	if err := loadGlobalConfig(configType); err != nil {
+		return nil, err
+	}
+
+	db, err := conf.C().MySQL.GetDB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewMigrator(db)
+}
+
+// 检查数据库结构是否为最新版本, 依赖全局配置先初始化
+func checkSchema() error {
+	db, err := conf.C().MySQL.GetDB()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	return m.Check(context.Background())
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVarP(&configType, "config_type", "t", "file", "the restful-api demo config type")
+	migrateCmd.PersistentFlags().StringVarP(&confFile, "config_file", "f", "etc/restful-api.toml", "the restful-api config file path")
+	migrateUpCmd.Flags().IntVar(&migrateTarget, "to", 0, "migrate up to the target version, 0 means latest")
+	migrateDownCmd.Flags().IntVarP(&migrateSteps, "steps", "n", 1, "the number of migrations to roll back")
+
+	migrateCmd.AddCommand(migrateStatusCmd, migrateUpCmd, migrateDownCmd)
+	RootCmd.AddCommand(migrateCmd)
+}
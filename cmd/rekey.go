@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	accountImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/secret"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rekeyNewKey string
+)
+
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "轮换敏感数据的加密密钥",
+	Long: `使用新的密钥重新加密已经入库的敏感数据, 当前密钥为配置中的app.key
+执行前需要停止所有服务实例, 执行完成后把配置中的app.key修改为新的密钥再启动服务
+--new_key 为空时使用当前密钥, 用于加密开启加密之前写入的明文数据`,
+	RunE: func(c *cobra.Command, args []string) error {
+		if err := loadGlobalConfig(configType); err != nil {
+			return err
+		}
+		if err := loadGlobalLogger(); err != nil {
+			return err
+		}
+		if conf.C().App.Storage == conf.MemoryStorage {
+			return fmt.Errorf("memory storage does not persist data, no need to rekey")
+		}
+		if err := checkSchema(); err != nil {
+			return err
+		}
+		if err := impl.Service.Init(); err != nil {
+			return err
+		}
+		if err := accountImpl.Service.Init(); err != nil {
+			return err
+		}
+
+		newKey := rekeyNewKey
+		if newKey == "" {
+			newKey = conf.C().App.Key
+		}
+		to, err := secret.NewCipher(newKey)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		result, err := impl.Service.Rekey(ctx, to)
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+		if err != nil {
+			return err
+		}
+		n, err := accountImpl.Service.Rekey(ctx, to)
+		fmt.Printf("%d cloud accounts rekeyed\n", n)
+		if err != nil {
+			return err
+		}
+		if rekeyNewKey != "" {
+			fmt.Println("rekey finished, please set app.key to the new key before starting the service")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rekeyCmd.PersistentFlags().StringVarP(&configType, "config_type", "t", "file", "the restful-api demo config type")
+	rekeyCmd.PersistentFlags().StringVarP(&confFile, "config_file", "f", "etc/restful-api.toml", "the restful-api config file path")
+	rekeyCmd.Flags().StringVar(&rekeyNewKey, "new_key", "", "the new app key, empty means re-encrypt with the current key")
+	RootCmd.AddCommand(rekeyCmd)
+}
@@ -1,14 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	accountImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account/impl"
+	apikeyImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/memory"
+	namespaceImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace/impl"
+	policyImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy/impl"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder"
+	reminderImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/reminder/impl"
+	syncImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer/impl"
+	userImpl "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user/impl"
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol"
 
@@ -38,11 +50,30 @@ var startCmd = &cobra.Command{
 		}
 
 		// 初始化服务层 Ioc 初始化
-		if err := impl.Service.Init(); err != nil {
+		if err := loadHostService(); err != nil {
+			return err
+		}
+		if err := loadNamespaceService(); err != nil {
+			return err
+		}
+		if err := loadAccountService(); err != nil {
+			return err
+		}
+		if err := loadSyncService(); err != nil {
+			return err
+		}
+		if err := loadReminderService(); err != nil {
+			return err
+		}
+		if err := loadUserService(); err != nil {
+			return err
+		}
+		if err := loadPolicyService(); err != nil {
+			return err
+		}
+		if err := loadAPIKeyService(); err != nil {
 			return err
 		}
-		// 把服务实例注册给IOC层
-		apps.Host = impl.Service
 
 		// 启动服务后, 需要处理的事件
 		ch := make(chan os.Signal, 1)
@@ -60,10 +91,13 @@ var startCmd = &cobra.Command{
 }
 
 func NewService(conf *conf.Config) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Service{
-		conf: conf,
-		http: protocol.NewHTTPService(),
-		log:  zap.L().Named("service"),
+		conf:   conf,
+		http:   protocol.NewHTTPService(),
+		log:    zap.L().Named("service"),
+		ctx:    ctx,
+		cancel: cancel,
 	}
 }
 
@@ -74,12 +108,76 @@ type Service struct {
 	conf *conf.Config
 	http *protocol.HTTPService
 	log  logger.Logger
+
+	// 后台任务的生命周期, 服务停止时取消
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func (s *Service) Start() error {
+	// 后台任务: 回收站清理
+	go s.runRecyclePurge(s.ctx)
+	// 后台任务: 主机过期提醒
+	go s.runExpireReminder(s.ctx)
+
 	return s.http.Start()
 }
 
+// 定期彻底清除回收站中超过保留期的主机
+func (s *Service) runRecyclePurge(ctx context.Context) {
+	rc := s.conf.Recycle
+	if rc.RetentionDays <= 0 || rc.PurgeInterval <= 0 {
+		s.log.Infof("recycle purge disabled")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(rc.PurgeInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		resp, err := apps.Host.PurgeHost(ctx, host.NewPurgeHostRequest(rc.PurgeBefore(time.Now())))
+		if err != nil {
+			s.log.Errorf("purge recycle hosts error, %s", err)
+		} else if resp.Count > 0 {
+			s.log.Infof("purge %d hosts deleted %d days ago", resp.Count, rc.RetentionDays)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 定期扫描即将过期和已经过期的主机, 发送过期提醒
+func (s *Service) runExpireReminder(ctx context.Context) {
+	rc := s.conf.Reminder
+	if rc.Interval <= 0 || rc.WithinDays <= 0 {
+		s.log.Infof("expire reminder disabled")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(rc.Interval) * time.Second)
+	defer ticker.Stop()
+
+	req := reminder.NewRemindRequest(time.Duration(rc.WithinDays) * 24 * time.Hour)
+	for {
+		resp, err := apps.Reminder.Remind(ctx, req)
+		if err != nil {
+			s.log.Errorf("remind expiring hosts error, %s", err)
+		} else if resp.Sent > 0 || resp.Failed > 0 {
+			s.log.Infof("remind expiring hosts, sent: %d, failed: %d, errors: %v", resp.Sent, resp.Failed, resp.Errors)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // 当发现用户收到终止掉程序的时候, 要完成处理
 func (s *Service) waitSign(sign chan os.Signal) {
 	for sg := range sign {
@@ -92,6 +190,7 @@ func (s *Service) waitSign(sign chan os.Signal) {
 		default:
 			// 资源管理
 			s.log.Infof("receive signal '%v', start graceful shudown", v.String())
+			s.cancel()
 			if err := s.http.Stop(); err != nil {
 				s.log.Errorf("graceful shudown err: %s, force exit", err)
 			}
@@ -124,6 +223,94 @@ func loadGlobalConfig(configType string) error {
 	return nil
 }
 
+// 根据配置的存储类型初始化host服务, 并把服务实例注册给IOC层
+func loadHostService() error {
+	switch conf.C().App.Storage {
+	case conf.MySQLStorage, "":
+		// 数据库结构不是最新版本时拒绝启动
+		if err := checkSchema(); err != nil {
+			return err
+		}
+		if err := impl.Service.Init(); err != nil {
+			return err
+		}
+		apps.Host = impl.Service
+	case conf.MemoryStorage:
+		if err := memory.Service.Init(); err != nil {
+			return err
+		}
+		apps.Host = memory.Service
+	default:
+		return fmt.Errorf("unknown storage type %s", conf.C().App.Storage)
+	}
+	return nil
+}
+
+// 命名空间服务依赖host服务, 需要在host服务之后初始化
+func loadNamespaceService() error {
+	if err := namespaceImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.Namespace = namespaceImpl.Service
+	return nil
+}
+
+// 账号只支持MySQL存储, 内存存储时不提供账号服务
+func loadAccountService() error {
+	if conf.C().App.Storage == conf.MemoryStorage {
+		return nil
+	}
+	if err := accountImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.Account = accountImpl.Service
+	return nil
+}
+
+// 同步服务依赖host服务, 需要在host服务之后初始化
+// 同步配置通过account_id引用账号时, 还依赖账号服务
+func loadSyncService() error {
+	if err := syncImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.Sync = syncImpl.Service
+	return nil
+}
+
+// 过期提醒服务依赖host服务, 需要在host服务之后初始化
+func loadReminderService() error {
+	if err := reminderImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.Reminder = reminderImpl.Service
+	return nil
+}
+
+// 用户和令牌和主机数据使用相同的存储
+func loadUserService() error {
+	if err := userImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.User = userImpl.Service
+	return nil
+}
+
+func loadPolicyService() error {
+	if err := policyImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.Policy = policyImpl.Service
+	return nil
+}
+
+func loadAPIKeyService() error {
+	if err := apikeyImpl.Service.Init(); err != nil {
+		return err
+	}
+	apps.APIKey = apikeyImpl.Service
+	return nil
+}
+
 // log 为全局变量, 只需要load 即可全局可用户, 依赖全局配置先初始化
 func loadGlobalLogger() error {
 	var (
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncVendors  []string
+	syncAccounts []string
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "从云厂商同步主机信息",
+	Long:  `从配置的云厂商账号同步主机信息, 同步完成后输出同步结果`,
+	RunE: func(c *cobra.Command, args []string) error {
+		if err := loadGlobalConfig(configType); err != nil {
+			return err
+		}
+		if err := loadGlobalLogger(); err != nil {
+			return err
+		}
+		if err := loadHostService(); err != nil {
+			return err
+		}
+		if err := loadAccountService(); err != nil {
+			return err
+		}
+		if err := loadSyncService(); err != nil {
+			return err
+		}
+
+		req := syncer.NewSyncRequest()
+		for _, item := range syncVendors {
+			v, err := host.ParseVendor(item)
+			if err != nil {
+				return err
+			}
+			req.Vendor = append(req.Vendor, v)
+		}
+		req.Account = syncAccounts
+
+		report, err := apps.Sync.Sync(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+
+		// 有失败时以非0状态退出, 方便定时任务发现问题
+		if n := report.Failed(); n > 0 {
+			return fmt.Errorf("sync finished with %d failures", n)
+		}
+		return nil
+	},
+}
+
+func init() {
+	syncCmd.PersistentFlags().StringVarP(&configType, "config_type", "t", "file", "the restful-api demo config type")
+	syncCmd.PersistentFlags().StringVarP(&confFile, "config_file", "f", "etc/restful-api.toml", "the restful-api config file path")
+	syncCmd.Flags().StringSliceVar(&syncVendors, "vendor", nil, "only sync the accounts of these vendors, e.g. ALI_CLOUD,TX_CLOUD")
+	syncCmd.Flags().StringSliceVar(&syncAccounts, "account", nil, "only sync these accounts")
+	RootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	createUserReq = user.NewCreateUserRequest()
+	userRole      string
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "用户管理",
+	Long:  `用户管理, 用于初始化第一个用户和分配角色`,
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "创建用户",
+	RunE: func(c *cobra.Command, args []string) error {
+		if err := loadUserCommand(); err != nil {
+			return err
+		}
+
+		createUserReq.Role = user.Role(userRole)
+		u, err := apps.User.CreateUser(context.Background(), createUserReq)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("user %s created, id: %s, role: %s\n", u.Username, u.Id, u.Role)
+		return nil
+	},
+}
+
+var userSetRoleCmd = &cobra.Command{
+	Use:   "set-role <username> <role>",
+	Short: "修改用户的角色",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(c *cobra.Command, args []string) error {
+		if err := loadUserCommand(); err != nil {
+			return err
+		}
+
+		req := user.NewUpdateUserRoleRequest(args[0])
+		req.Role = user.Role(args[1])
+		u, err := apps.User.UpdateUserRole(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("user %s role: %s\n", u.Username, u.Role)
+		return nil
+	},
+}
+
+// 用户管理命令只在MySQL存储时可用
+func loadUserCommand() error {
+	if err := loadGlobalConfig(configType); err != nil {
+		return err
+	}
+	if err := loadGlobalLogger(); err != nil {
+		return err
+	}
+	if conf.C().App.Storage == conf.MemoryStorage {
+		return fmt.Errorf("memory storage does not persist users, use auth.bootstrap_user instead")
+	}
+	if err := checkSchema(); err != nil {
+		return err
+	}
+	return loadUserService()
+}
+
+func init() {
+	userCmd.PersistentFlags().StringVarP(&configType, "config_type", "t", "file", "the restful-api demo config type")
+	userCmd.PersistentFlags().StringVarP(&confFile, "config_file", "f", "etc/restful-api.toml", "the restful-api config file path")
+	userCreateCmd.Flags().StringVarP(&createUserReq.Username, "username", "u", "", "the username")
+	userCreateCmd.Flags().StringVarP(&createUserReq.Password, "password", "p", "", "the password")
+	userCreateCmd.Flags().StringVarP(&userRole, "role", "r", string(user.RoleViewer), "the user role, viewer/operator/admin")
+
+	userCmd.AddCommand(userCreateCmd, userSetRoleCmd)
+	RootCmd.AddCommand(userCmd)
+}
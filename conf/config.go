@@ -31,16 +31,24 @@ func SetGlobalConfig(conf *Config) {
 // 初始化默认配置
 func NewDefaultConfig() *Config {
 	return &Config{
-		App:   newDefaultApp(),
-		MySQL: newDefaultMySQL(),
-		Log:   newDefaultLog(),
+		App:      newDefaultApp(),
+		MySQL:    newDefaultMySQL(),
+		Log:      newDefaultLog(),
+		Recycle:  newDefaultRecycle(),
+		Sync:     newDefaultSync(),
+		Reminder: newDefaultReminder(),
+		Auth:     newDefaultAuth(),
 	}
 }
 
 type Config struct {
-	App   *app
-	MySQL *mysql
-	Log   *log
+	App      *app
+	MySQL    *mysql
+	Log      *log
+	Recycle  *recycle
+	Sync     *cloudSync
+	Reminder *reminder
+	Auth     *auth
 }
 
 // 配置是通过对象来进行映射的
@@ -48,10 +56,12 @@ type Config struct {
 
 func newDefaultApp() *app {
 	return &app{
-		Name: "restful-api",
-		Host: "127.0.0.1",
-		Port: "8050",
-		Key:  "default app key",
+		Name:    "restful-api",
+		Host:    "127.0.0.1",
+		Port:    "8050",
+		Key:     "default app key",
+		Storage: MySQLStorage,
+		Search:  LikeSearch,
 	}
 }
 
@@ -88,6 +98,12 @@ type app struct {
 	Port string `toml:"port"`
 	// 比较敏感的数据, 入库时加密后的数据, 加密的密钥就是该配置
 	Key string `toml:"key"`
+	// host服务的存储类型: mysql, memory
+	Storage StorageType `toml:"storage"`
+	// 修改和删除主机时是否必须携带If-Match Header
+	RequireIfMatch bool `toml:"require_if_match"`
+	// MySQL存储的关键字搜索方式: like, fulltext
+	Search SearchType `toml:"search"`
 }
 
 func (a *app) Addr() string {
@@ -158,3 +174,155 @@ type log struct {
 	Format  LogFormat `toml:"format" env:"LOG_FORMAT"`
 	To      LogTo     `toml:"to" env:"LOG_TO"`
 }
+
+func newDefaultRecycle() *recycle {
+	return &recycle{
+		RetentionDays: 30,
+		PurgeInterval: 60 * 60,
+	}
+}
+
+// 回收站配置, 软删除的主机超过保留期后会被彻底清除
+type recycle struct {
+	// 保留天数, 0表示永久保留
+	RetentionDays int `toml:"retention_days" env:"RECYCLE_RETENTION_DAYS"`
+	// 清理任务的执行间隔, 单位是秒
+	PurgeInterval int `toml:"purge_interval" env:"RECYCLE_PURGE_INTERVAL"`
+}
+
+// 在该时间之前删除的主机需要被清除, 13位时间戳
+func (r *recycle) PurgeBefore(now time.Time) int64 {
+	return now.Add(-time.Duration(r.RetentionDays)*24*time.Hour).UnixNano() / 1000000
+}
+
+func newDefaultSync() *cloudSync {
+	return &cloudSync{
+		Providers: []*SyncProvider{},
+	}
+}
+
+// 云厂商资源同步配置
+type cloudSync struct {
+	// 需要同步的账号, 每个账号对应一个Provider
+	Providers []*SyncProvider `toml:"providers"`
+}
+
+// 同步账号的配置
+type SyncProvider struct {
+	// 厂商: ALI_CLOUD, TX_CLOUD, HW_CLOUD
+	Vendor string `toml:"vendor"`
+	// 账号名称, 同步的主机会记录到SyncAccount
+	Account string `toml:"account"`
+	// 引用账号管理中的账号, 设置后厂商以账号为准, 同步的主机SyncAccount记录为账号Id
+	AccountId string `toml:"account_id"`
+	// 同步的主机归属的命名空间, 为空时使用default
+	Namespace string `toml:"namespace"`
+	// Provider的类型, 目前只支持从文件读取实例数据的file
+	Type ProviderType `toml:"type"`
+	// file 类型的数据文件路径
+	Path string `toml:"path"`
+}
+
+func newDefaultReminder() *reminder {
+	return &reminder{
+		Interval:   60 * 60,
+		WithinDays: 7,
+		Notifier:   LogNotifier,
+		SMTP: &SMTP{
+			Port:       "25",
+			MailboxDir: "mailbox",
+		},
+	}
+}
+
+// 主机过期提醒配置
+type reminder struct {
+	// 扫描间隔, 单位是秒, 0表示关闭提醒
+	Interval int `toml:"interval" env:"REMINDER_INTERVAL"`
+	// 提前多少天提醒
+	WithinDays int `toml:"within_days" env:"REMINDER_WITHIN_DAYS"`
+	// 提醒方式: log, webhook, smtp
+	Notifier NotifierType `toml:"notifier" env:"REMINDER_NOTIFIER"`
+	// webhook 方式的回调地址, 提醒内容以JSON格式POST到该地址
+	WebhookURL string `toml:"webhook_url" env:"REMINDER_WEBHOOK_URL"`
+	// smtp 方式的邮件配置
+	SMTP *SMTP `toml:"smtp"`
+}
+
+// 邮件服务器配置, Host为空时不发送邮件, 写入本地的MailboxDir目录
+type SMTP struct {
+	Host       string   `toml:"host" env:"SMTP_HOST"`
+	Port       string   `toml:"port" env:"SMTP_PORT"`
+	Username   string   `toml:"username" env:"SMTP_USERNAME"`
+	Password   string   `toml:"password" env:"SMTP_PASSWORD"`
+	From       string   `toml:"from" env:"SMTP_FROM"`
+	To         []string `toml:"to" env:"SMTP_TO" envSeparator:","`
+	MailboxDir string   `toml:"mailbox_dir" env:"SMTP_MAILBOX_DIR"`
+}
+
+func (s *SMTP) Addr() string {
+	return fmt.Sprintf("%s:%s", s.Host, s.Port)
+}
+
+func newDefaultAuth() *auth {
+	return &auth{
+		Enable:   true,
+		TokenTTL: 2 * 60 * 60,
+		JWT:      newDefaultJWT(),
+	}
+}
+
+// HTTP API 认证配置
+type auth struct {
+	// 关闭后所有接口都可以匿名访问, 只用于本地开发
+	Enable bool `toml:"enable" env:"AUTH_ENABLE"`
+	// 登录颁发的令牌有效期, 单位是秒
+	TokenTTL int `toml:"token_ttl" env:"AUTH_TOKEN_TTL"`
+	// 启动时用户不存在则自动创建, 用于初始化第一个用户, 内存存储时每次启动都需要
+	BootstrapUser     string `toml:"bootstrap_user" env:"AUTH_BOOTSTRAP_USER"`
+	BootstrapPassword string `toml:"bootstrap_password" env:"AUTH_BOOTSTRAP_PASSWORD"`
+	// 覆盖内置的角色权限
+	Policies []*RolePolicy `toml:"policies"`
+	// 校验内部身份提供方签发的JWT
+	JWT *JWTAuth `toml:"jwt"`
+}
+
+// 角色拥有的权限, 支持 * 和 host:* 通配
+type RolePolicy struct {
+	Role        string   `toml:"role"`
+	Permissions []string `toml:"permissions"`
+}
+
+func newDefaultJWT() *JWTAuth {
+	return &JWTAuth{
+		RefreshInterval: 60 * 60,
+		Leeway:          60,
+		UsernameClaim:   "preferred_username",
+		RoleClaim:       "roles",
+		RoleMapping:     map[string]string{},
+		DefaultRole:     "viewer",
+	}
+}
+
+// JWT认证配置, 开启后 Authorization: Bearer 中的JWT按RS256/ES256校验
+// 本地登录颁发的令牌不受影响
+type JWTAuth struct {
+	Enable bool `toml:"enable" env:"AUTH_JWT_ENABLE"`
+	// JWKS的来源, 本地文件路径或者 http(s) URL
+	JWKS string `toml:"jwks" env:"AUTH_JWT_JWKS"`
+	// 重新加载JWKS的间隔, 单位是秒, 0表示只在遇到未知kid时重新加载
+	RefreshInterval int `toml:"refresh_interval" env:"AUTH_JWT_REFRESH_INTERVAL"`
+	// 为空时不校验
+	Issuer   string `toml:"issuer" env:"AUTH_JWT_ISSUER"`
+	Audience string `toml:"audience" env:"AUTH_JWT_AUDIENCE"`
+	// 允许的时钟偏差, 单位是秒
+	Leeway int `toml:"leeway" env:"AUTH_JWT_LEEWAY"`
+	// 用户名取自该声明, 不存在时使用sub
+	UsernameClaim string `toml:"username_claim" env:"AUTH_JWT_USERNAME_CLAIM"`
+	// 角色取自该声明, 支持字符串和字符串数组
+	RoleClaim string `toml:"role_claim" env:"AUTH_JWT_ROLE_CLAIM"`
+	// 声明中的值 --> 角色, 为空时声明中的值直接作为角色名
+	RoleMapping map[string]string `toml:"role_mapping" env:"AUTH_JWT_ROLE_MAPPING"`
+	// 没有匹配到角色时使用的角色, 为空时拒绝访问
+	DefaultRole string `toml:"default_role" env:"AUTH_JWT_DEFAULT_ROLE"`
+}
@@ -0,0 +1,41 @@
+package conf
+
+// StorageType host服务使用的存储类型
+type StorageType string
+
+const (
+	// MySQLStorage 基于MySQL存储
+	MySQLStorage = StorageType("mysql")
+	// MemoryStorage 基于内存存储, 用于本地开发和测试
+	MemoryStorage = StorageType("memory")
+)
+
+// ProviderType 云厂商资源同步的Provider类型
+type ProviderType string
+
+const (
+	// FileProvider 从本地JSON文件读取实例数据, 用于离线开发和测试
+	FileProvider = ProviderType("file")
+)
+
+// NotifierType 主机过期提醒的通知方式
+type NotifierType string
+
+const (
+	// LogNotifier 写入服务日志
+	LogNotifier = NotifierType("log")
+	// WebhookNotifier 以JSON格式POST到回调地址
+	WebhookNotifier = NotifierType("webhook")
+	// SMTPNotifier 发送邮件
+	SMTPNotifier = NotifierType("smtp")
+)
+
+// SearchType MySQL存储的关键字搜索方式
+type SearchType string
+
+const (
+	// LikeSearch 使用LIKE匹配, 不依赖索引, 数据量大时较慢
+	LikeSearch = SearchType("like")
+	// FulltextSearch 使用FULLTEXT索引, 按分词匹配
+	FulltextSearch = SearchType("fulltext")
+)
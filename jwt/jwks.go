@@ -0,0 +1,269 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// 遇到未知kid时最短的重新加载间隔, 避免伪造的kid打爆身份提供方
+	minReloadInterval = 10 * time.Second
+	// 下载JWKS的超时时间
+	fetchTimeout = 10 * time.Second
+)
+
+// JWKS中的一个公钥, 只支持RSA和P-256椭圆曲线
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// 解析JWKS文档, 不支持的密钥类型直接跳过
+func parseJWKS(data []byte) ([]*publicKey, error) {
+	doc := &jwks{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("parse jwks error, %s", err)
+	}
+
+	keys := []*publicKey{}
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pk, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse jwk %s error, %s", k.Kid, err)
+		}
+		if pk == nil {
+			continue
+		}
+		keys = append(keys, &publicKey{kid: k.Kid, alg: k.Alg, key: pk})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks has no supported signing key")
+	}
+	return keys, nil
+}
+
+func (k *JSONWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("ec point is not on curve P-256")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// 从文件或者URL加载JWKS, 超过刷新间隔或者遇到未知的kid时重新加载
+// 重新加载失败时继续使用上一次加载成功的公钥
+func NewKeySet(source string, refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		source:          source,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+type KeySet struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	lock     sync.Mutex
+	keys     []*publicKey
+	loadAt   time.Time
+	reloadAt time.Time
+	// 正在进行的重新加载, 并发的请求共用同一次下载
+	loading *reloadCall
+}
+
+type reloadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// 立即重新加载
+func (s *KeySet) Refresh(ctx context.Context) error {
+	return s.reload(ctx, 0)
+}
+
+// 距离上一次重新加载不足interval时跳过, 下载在锁外进行, 并且不使用请求的context,
+// 请求取消时只是不再等待, 不会中断共用的下载
+func (s *KeySet) reload(ctx context.Context, interval time.Duration) error {
+	s.lock.Lock()
+	call := s.loading
+	if call == nil {
+		if interval > 0 && time.Since(s.reloadAt) < interval {
+			s.lock.Unlock()
+			return nil
+		}
+		call = &reloadCall{done: make(chan struct{})}
+		s.loading, s.reloadAt = call, time.Now()
+		go s.fetch(call)
+	}
+	s.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *KeySet) fetch(call *reloadCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	var keys []*publicKey
+	data, err := s.read(ctx)
+	if err != nil {
+		err = fmt.Errorf("load jwks from %s error, %s", s.source, err)
+	} else {
+		keys, err = parseJWKS(data)
+	}
+
+	s.lock.Lock()
+	if err == nil {
+		s.keys, s.loadAt = keys, time.Now()
+	}
+	s.loading = nil
+	s.lock.Unlock()
+
+	call.err = err
+	close(call.done)
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !isURL(s.source) {
+		return os.ReadFile(s.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func isURL(s string) bool {
+	return len(s) > 8 && (s[:7] == "http://" || s[:8] == "https://")
+}
+
+// 查找和kid, alg匹配的公钥, kid为空时返回所有算法匹配的公钥
+func (s *KeySet) lookup(ctx context.Context, kid, alg string) ([]*publicKey, error) {
+	s.lock.Lock()
+	loaded := s.keys != nil
+	expired := s.refreshInterval > 0 && time.Since(s.loadAt) > s.refreshInterval
+	s.lock.Unlock()
+
+	// 超过刷新间隔, 重新加载, 失败时继续使用旧的公钥
+	if !loaded {
+		if err := s.reload(ctx, 0); err != nil {
+			return nil, err
+		}
+	} else if expired {
+		_ = s.reload(ctx, minReloadInterval)
+	}
+
+	matched := s.match(kid, alg)
+	// 身份提供方轮换了密钥, 伪造的kid也会走到这里, 需要限制频率
+	if len(matched) == 0 && kid != "" {
+		if err := s.reload(ctx, minReloadInterval); err != nil {
+			return nil, err
+		}
+		matched = s.match(kid, alg)
+	}
+	return matched, nil
+}
+
+func (s *KeySet) match(kid, alg string) []*publicKey {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	matched := []*publicKey{}
+	for _, k := range s.keys {
+		if kid != "" && k.kid != kid {
+			continue
+		}
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+		matched = append(matched, k)
+	}
+	return matched
+}
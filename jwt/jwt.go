@@ -0,0 +1,185 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// 令牌是否是JWT格式: header.payload.signature
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// JWT的载荷, 保留原始的声明, 用于映射用户名和角色
+type Claims map[string]interface{}
+
+func (c Claims) String(name string) string {
+	v, _ := c[name].(string)
+	return v
+}
+
+// 字符串或者字符串数组类型的声明
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// 数值类型的时间声明(秒), 不存在时返回false
+func (c Claims) time(name string) (time.Time, bool) {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+func NewVerifier(keys *KeySet) *Verifier {
+	return &Verifier{
+		keys: keys,
+		now:  time.Now,
+	}
+}
+
+// 校验JWT的签名, 签发方, 受众和有效期
+type Verifier struct {
+	keys *KeySet
+	// 为空时不校验
+	Issuer   string
+	Audience string
+	// 允许的时钟偏差
+	Leeway time.Duration
+
+	now func() time.Time
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a jwt")
+	}
+
+	h := &header{}
+	if err := decodeSegment(parts[0], h); err != nil {
+		return nil, fmt.Errorf("decode jwt header error, %s", err)
+	}
+	if h.Alg != RS256 && h.Alg != ES256 {
+		return nil, fmt.Errorf("jwt alg %s not supported, supported: RS256, ES256", h.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode jwt signature error, %s", err)
+	}
+	keys, err := v.keys.lookup(ctx, h.Kid, h.Alg)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	verified := false
+	for _, k := range keys {
+		if verify(h.Alg, k.key, digest[:], sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("jwt signature invalid")
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decode jwt claims error, %s", err)
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(c Claims) error {
+	now := v.now()
+
+	exp, ok := c.time("exp")
+	if !ok {
+		return fmt.Errorf("jwt exp claim required")
+	}
+	if !now.Before(exp.Add(v.Leeway)) {
+		return fmt.Errorf("jwt expired")
+	}
+	if nbf, ok := c.time("nbf"); ok && now.Add(v.Leeway).Before(nbf) {
+		return fmt.Errorf("jwt not valid yet")
+	}
+
+	if v.Issuer != "" && c.String("iss") != v.Issuer {
+		return fmt.Errorf("jwt issuer %s not trusted", c.String("iss"))
+	}
+	if v.Audience != "" {
+		matched := false
+		for _, aud := range c.Strings("aud") {
+			if aud == v.Audience {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("jwt audience does not include %s", v.Audience)
+		}
+	}
+	return nil
+}
+
+func verify(alg string, key crypto.PublicKey, digest, sig []byte) bool {
+	switch alg {
+	case RS256:
+		pk, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pk, crypto.SHA256, digest, sig) == nil
+	case ES256:
+		pk, ok := key.(*ecdsa.PublicKey)
+		// JWS 中的ES256签名是固定长度的 r||s
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pk, digest, r, s)
+	}
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
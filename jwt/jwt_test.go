@@ -0,0 +1,159 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/jwt"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !should.NoError(err) {
+		return
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !should.NoError(err) {
+		return
+	}
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	should.NoError(writeJWKS(file, &rk.PublicKey, ek))
+
+	v := jwt.NewVerifier(jwt.NewKeySet(file, time.Hour))
+	v.Issuer, v.Audience = "https://idp.example.com", "restful-api"
+
+	claims := map[string]interface{}{
+		"iss":                "https://idp.example.com",
+		"aud":                []string{"restful-api"},
+		"sub":                "u-001",
+		"preferred_username": "alice",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+	}
+
+	c, err := v.Verify(ctx, sign(t, "RS256", "rsa-1", rk, claims))
+	if should.NoError(err) {
+		should.Equal("alice", c.String("preferred_username"))
+	}
+	_, err = v.Verify(ctx, sign(t, "ES256", "ec-1", ek, claims))
+	should.NoError(err)
+
+	// 签名和kid不匹配
+	_, err = v.Verify(ctx, sign(t, "RS256", "ec-1", rk, claims))
+	should.Error(err)
+
+	claims["aud"] = "other"
+	_, err = v.Verify(ctx, sign(t, "RS256", "rsa-1", rk, claims))
+	should.Error(err)
+
+	claims["aud"] = "restful-api"
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = v.Verify(ctx, sign(t, "RS256", "rsa-1", rk, claims))
+	should.Error(err)
+
+	_, err = v.Verify(ctx, "eyJhbGciOiJub25lIn0.e30.")
+	should.Error(err)
+}
+
+func TestUnknownKidReload(t *testing.T) {
+	should := assert.New(t)
+	ctx := context.Background()
+
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !should.NoError(err) {
+		return
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !should.NoError(err) {
+		return
+	}
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	should.NoError(writeJWKS(file, &rk.PublicKey, ek))
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.ServeFile(w, r, file)
+	}))
+	defer srv.Close()
+
+	keys := jwt.NewKeySet(srv.URL, time.Hour)
+	should.NoError(keys.Refresh(ctx))
+	v := jwt.NewVerifier(keys)
+
+	// 刚加载过, 未知的kid不会再次请求身份提供方
+	token := sign(t, "RS256", "rsa-2", rk, map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()})
+	wg := sync.WaitGroup{}
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Verify(ctx, token)
+			should.Error(err)
+		}()
+	}
+	wg.Wait()
+	should.Equal(int32(1), atomic.LoadInt32(&hits))
+}
+
+func writeJWKS(file string, rk *rsa.PublicKey, ek *ecdsa.PrivateKey) error {
+	b64 := base64.RawURLEncoding.EncodeToString
+	doc := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kid": "rsa-1", "kty": "RSA", "alg": "RS256", "use": "sig",
+				"n": b64(rk.N.Bytes()), "e": b64(big.NewInt(int64(rk.E)).Bytes())},
+			{"kid": "ec-1", "kty": "EC", "crv": "P-256",
+				"x": b64(ek.X.FillBytes(make([]byte, 32))), "y": b64(ek.Y.FillBytes(make([]byte, 32)))},
+		},
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	h, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	p, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(p)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
@@ -0,0 +1,246 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/infraboard/mcube/types/ftime"
+)
+
+// 所有的迁移脚本, 命名规则: <版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql
+//
+//go:embed sql/*.sql
+var scripts embed.FS
+
+const (
+	createVersionTableSQL = `CREATE TABLE IF NOT EXISTS schema_version (
+		version int NOT NULL,
+		name varchar(255) NOT NULL DEFAULT '',
+		applied_at bigint(13) NOT NULL DEFAULT 0,
+		PRIMARY KEY (version)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+
+	queryVersionSQL  = `SELECT version, applied_at FROM schema_version`
+	insertVersionSQL = `INSERT INTO schema_version (version, name, applied_at) VALUES (?,?,?)`
+	deleteVersionSQL = `DELETE FROM schema_version WHERE version=?`
+)
+
+// 一个版本的迁移脚本
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+// 迁移脚本的执行状态
+type Status struct {
+	*Migration
+	Applied   bool  `json:"applied"`
+	AppliedAt int64 `json:"applied_at"`
+}
+
+// 加载内置的迁移脚本, 按版本号升序排列
+func Load() ([]*Migration, error) {
+	files, err := scripts.ReadDir("sql")
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[int]*Migration{}
+	for _, f := range files {
+		var (
+			name = f.Name()
+			up   bool
+		)
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			up = true
+			name = strings.TrimSuffix(name, ".up.sql")
+		case strings.HasSuffix(name, ".down.sql"):
+			name = strings.TrimSuffix(name, ".down.sql")
+		default:
+			return nil, fmt.Errorf("migration file %s must end with .up.sql or .down.sql", f.Name())
+		}
+
+		parts := strings.SplitN(name, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("migration file %s name format must be <version>_<name>", f.Name())
+		}
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s version is not a number, %s", f.Name(), err)
+		}
+
+		content, err := scripts.ReadFile(path.Join("sql", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := index[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			index[version] = m
+		}
+		if m.Name != parts[1] {
+			return nil, fmt.Errorf("migration version %d has different names: %s, %s", version, m.Name, parts[1])
+		}
+		if up {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(index))
+	for _, m := range index {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s requires both up and down scripts", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// 基于schema_version表管理数据库结构版本
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+// 查询已经执行过的版本, version --> applied_at
+func (m *Migrator) applied(ctx context.Context) (map[int]int64, error) {
+	if _, err := m.db.ExecContext(ctx, createVersionTableSQL); err != nil {
+		return nil, fmt.Errorf("create schema_version table error, %s", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, queryVersionSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query schema_version error, %s", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]int64{}
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt int64
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// 所有迁移脚本的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make([]*Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		at, ok := applied[mg.Version]
+		set = append(set, &Status{Migration: mg, Applied: ok, AppliedAt: at})
+	}
+	return set, nil
+}
+
+// 执行所有未执行的迁移, target > 0 时只执行到该版本为止
+func (m *Migrator) Up(ctx context.Context, target int) ([]*Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for _, s := range status {
+		if s.Applied {
+			continue
+		}
+		if target > 0 && s.Version > target {
+			break
+		}
+		if err := m.exec(ctx, s.Up, insertVersionSQL, s.Version, s.Name, ftime.Now().Timestamp()); err != nil {
+			return done, fmt.Errorf("migrate up %d_%s error, %s", s.Version, s.Name, err)
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// 按版本倒序回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for i := len(status) - 1; i >= 0 && len(done) < steps; i-- {
+		s := status[i]
+		if !s.Applied {
+			continue
+		}
+		if err := m.exec(ctx, s.Down, deleteVersionSQL, s.Version); err != nil {
+			return done, fmt.Errorf("migrate down %d_%s error, %s", s.Version, s.Name, err)
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// 检查数据库结构是否为最新版本
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := []string{}
+	for _, s := range status {
+		if !s.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is out of date, pending migrations: %s, please run 'restful-api migrate up' first",
+			strings.Join(pending, ","))
+	}
+	return nil
+}
+
+// 执行迁移脚本, 并记录版本变更
+// 注意: MySQL的DDL语句会隐式提交, 无法通过事务回滚, 脚本执行失败时需要人工介入
+func (m *Migrator) exec(ctx context.Context, script, versionSQL string, args ...interface{}) error {
+	if _, err := m.db.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := m.db.ExecContext(ctx, versionSQL, args...); err != nil {
+		return err
+	}
+	return nil
+}
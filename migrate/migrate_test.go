@@ -0,0 +1,24 @@
+package migrate_test
+
+import (
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/migrate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	should := assert.New(t)
+
+	migrations, err := migrate.Load()
+	if should.NoError(err) && should.NotEmpty(migrations) {
+		for i, m := range migrations {
+			should.NotEmpty(m.Up)
+			should.NotEmpty(m.Down)
+			if i > 0 {
+				should.Greater(m.Version, migrations[i-1].Version)
+			}
+		}
+	}
+}
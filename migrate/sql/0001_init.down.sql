@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `host`;
+DROP TABLE IF EXISTS `resource`;
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS `resource` (
+  `id` varchar(64) NOT NULL COMMENT '全局唯一Id',
+  `vendor` tinyint(1) NOT NULL DEFAULT 0 COMMENT '厂商',
+  `region` varchar(64) NOT NULL DEFAULT '' COMMENT '地域',
+  `zone` varchar(64) NOT NULL DEFAULT '' COMMENT '区域',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `expire_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '过期时间',
+  `category` varchar(64) NOT NULL DEFAULT '' COMMENT '种类',
+  `type` varchar(120) NOT NULL DEFAULT '' COMMENT '规格',
+  `instance_id` varchar(120) NOT NULL DEFAULT '' COMMENT '实例id',
+  `name` varchar(255) NOT NULL DEFAULT '' COMMENT '名称',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  `status` varchar(64) NOT NULL DEFAULT '' COMMENT '服务商中的状态',
+  `update_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '更新时间',
+  `sync_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '同步时间',
+  `sync_account` varchar(255) NOT NULL DEFAULT '' COMMENT '同步的账号',
+  `public_ip` varchar(64) NOT NULL DEFAULT '' COMMENT '公网IP',
+  `private_ip` varchar(64) NOT NULL DEFAULT '' COMMENT '内网IP',
+  `pay_type` varchar(64) NOT NULL DEFAULT '' COMMENT '实例付费方式',
+  `resource_hash` varchar(255) NOT NULL DEFAULT '' COMMENT '基础数据Hash',
+  `describe_hash` varchar(255) NOT NULL DEFAULT '' COMMENT '描述数据Hash',
+  PRIMARY KEY (`id`),
+  KEY `idx_name` (`name`),
+  KEY `idx_instance_id` (`instance_id`),
+  KEY `idx_create_at` (`create_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `host` (
+  `resource_id` varchar(64) NOT NULL COMMENT '关联Resource',
+  `cpu` tinyint(4) NOT NULL DEFAULT 0 COMMENT '核数',
+  `memory` int(13) NOT NULL DEFAULT 0 COMMENT '内存',
+  `gpu_amount` tinyint(4) NOT NULL DEFAULT 0 COMMENT 'GPU数量',
+  `gpu_spec` varchar(255) NOT NULL DEFAULT '' COMMENT 'GPU类型',
+  `os_type` varchar(255) NOT NULL DEFAULT '' COMMENT '操作系统类型',
+  `os_name` varchar(255) NOT NULL DEFAULT '' COMMENT '操作系统名称',
+  `serial_number` varchar(120) NOT NULL DEFAULT '' COMMENT '序列号',
+  `image_id` varchar(120) NOT NULL DEFAULT '' COMMENT '镜像ID',
+  `internet_max_bandwidth_out` int(10) NOT NULL DEFAULT 0 COMMENT '公网出带宽最大值',
+  `internet_max_bandwidth_in` int(10) NOT NULL DEFAULT 0 COMMENT '公网入带宽最大值',
+  `key_pair_name` varchar(255) NOT NULL DEFAULT '' COMMENT '密钥对名称',
+  `security_groups` varchar(255) NOT NULL DEFAULT '' COMMENT '安全组',
+  PRIMARY KEY (`resource_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS `resource_tag` (
+  `resource_id` varchar(64) NOT NULL COMMENT '关联Resource',
+  `t_key` varchar(255) NOT NULL COMMENT '标签的Key',
+  `t_value` varchar(255) NOT NULL DEFAULT '' COMMENT '标签的Value',
+  PRIMARY KEY (`resource_id`, `t_key`),
+  KEY `idx_key_value` (`t_key`, `t_value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
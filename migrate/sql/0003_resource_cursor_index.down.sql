@@ -0,0 +1 @@
+ALTER TABLE `resource` DROP INDEX `idx_create_at_id`, ADD INDEX `idx_create_at` (`create_at`);
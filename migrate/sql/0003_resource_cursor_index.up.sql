@@ -0,0 +1 @@
+ALTER TABLE `resource` DROP INDEX `idx_create_at`, ADD INDEX `idx_create_at_id` (`create_at`, `id`);
@@ -0,0 +1,4 @@
+ALTER TABLE `resource`
+  DROP INDEX `idx_deleted_at`,
+  DROP COLUMN `deleted_by`,
+  DROP COLUMN `deleted_at`;
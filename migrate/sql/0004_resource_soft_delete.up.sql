@@ -0,0 +1,4 @@
+ALTER TABLE `resource`
+  ADD COLUMN `deleted_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '删除时间, 0表示未删除',
+  ADD COLUMN `deleted_by` varchar(255) NOT NULL DEFAULT '' COMMENT '删除人',
+  ADD INDEX `idx_deleted_at` (`deleted_at`);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `host_revision` (
+  `resource_id` varchar(64) NOT NULL COMMENT '关联Resource',
+  `revision` int NOT NULL COMMENT '版本号, 从1开始递增',
+  `action` varchar(32) NOT NULL DEFAULT '' COMMENT '产生该版本的操作',
+  `operator` varchar(255) NOT NULL DEFAULT '' COMMENT '操作人',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '版本创建时间',
+  `data` longtext NOT NULL COMMENT '该版本主机的完整数据(JSON)',
+  PRIMARY KEY (`resource_id`, `revision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
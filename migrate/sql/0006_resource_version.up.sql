@@ -0,0 +1 @@
+ALTER TABLE `resource` ADD COLUMN `version` bigint NOT NULL DEFAULT 1 COMMENT '乐观锁版本号, 每次修改加1';
@@ -0,0 +1,3 @@
+ALTER TABLE `resource`
+  DROP INDEX `idx_describe_changed_at`,
+  DROP COLUMN `describe_changed_at`;
@@ -0,0 +1,5 @@
+ALTER TABLE `resource`
+  ADD COLUMN `describe_changed_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '描述数据Hash最近一次变化的时间',
+  ADD INDEX `idx_describe_changed_at` (`describe_changed_at`);
+
+UPDATE `resource` SET `describe_changed_at` = GREATEST(`create_at`, `update_at`);
@@ -0,0 +1,3 @@
+ALTER TABLE `resource`
+  DROP INDEX `uk_vendor_instance`,
+  DROP COLUMN `instance_key`;
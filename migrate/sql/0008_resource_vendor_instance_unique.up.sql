@@ -0,0 +1,4 @@
+-- 实例Id为空的主机不参与唯一约束, 通过生成列把空字符串转换为NULL
+ALTER TABLE `resource`
+  ADD COLUMN `instance_key` varchar(120) GENERATED ALWAYS AS (NULLIF(`instance_id`, '')) VIRTUAL COMMENT '参与唯一约束的实例id',
+  ADD UNIQUE INDEX `uk_vendor_instance` (`vendor`, `instance_key`);
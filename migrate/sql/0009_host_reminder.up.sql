@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS `host_reminder` (
+  `resource_id` varchar(64) NOT NULL COMMENT '关联Resource',
+  `expire_at` bigint(13) NOT NULL COMMENT '提醒时主机的过期时间',
+  `stage` varchar(32) NOT NULL COMMENT '提醒窗口: expiring, expired',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '提醒发送时间',
+  PRIMARY KEY (`resource_id`, `expire_at`, `stage`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
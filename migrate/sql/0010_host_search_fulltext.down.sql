@@ -0,0 +1,2 @@
+ALTER TABLE `host` DROP INDEX `ft_host_search`;
+ALTER TABLE `resource` DROP INDEX `ft_resource_search`;
@@ -0,0 +1,3 @@
+-- search = "fulltext" 时使用的全文索引
+ALTER TABLE `resource` ADD FULLTEXT INDEX `ft_resource_search` (`name`, `description`, `instance_id`, `public_ip`, `private_ip`);
+ALTER TABLE `host` ADD FULLTEXT INDEX `ft_host_search` (`serial_number`, `security_groups`);
@@ -0,0 +1,4 @@
+ALTER TABLE `host`
+  MODIFY COLUMN `key_pair_name` varchar(255) NOT NULL DEFAULT '' COMMENT '密钥对名称';
+ALTER TABLE `resource`
+  MODIFY COLUMN `sync_account` varchar(255) NOT NULL DEFAULT '' COMMENT '同步的账号';
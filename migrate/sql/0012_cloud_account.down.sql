@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `cloud_account`;
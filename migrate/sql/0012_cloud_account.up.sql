@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `cloud_account` (
+  `id` varchar(64) NOT NULL COMMENT '账号Id',
+  `vendor` tinyint(1) NOT NULL DEFAULT 0 COMMENT '厂商',
+  `name` varchar(120) NOT NULL DEFAULT '' COMMENT '账号名称',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  `access_key` varchar(512) NOT NULL DEFAULT '' COMMENT 'AccessKey, 加密存储',
+  `secret` varchar(512) NOT NULL DEFAULT '' COMMENT 'Secret, 加密存储',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `update_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_vendor_name` (`vendor`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `user_token`;
+DROP TABLE IF EXISTS `user`;
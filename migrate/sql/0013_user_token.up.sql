@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` varchar(64) NOT NULL COMMENT '用户Id',
+  `username` varchar(120) NOT NULL COMMENT '用户名',
+  `password` varchar(255) NOT NULL DEFAULT '' COMMENT 'bcrypt哈希后的密码',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `update_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_token` (
+  `token_key` varchar(64) NOT NULL COMMENT '令牌的sha256摘要',
+  `user_id` varchar(64) NOT NULL COMMENT '关联用户',
+  `username` varchar(120) NOT NULL DEFAULT '' COMMENT '用户名',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '颁发时间',
+  `expire_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '过期时间',
+  PRIMARY KEY (`token_key`),
+  KEY `idx_expire_at` (`expire_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
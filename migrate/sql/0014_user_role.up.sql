@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `role` varchar(32) NOT NULL DEFAULT 'viewer' COMMENT '角色: viewer/operator/admin' AFTER `password`;
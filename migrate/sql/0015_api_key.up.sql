@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `api_key` (
+  `id` varchar(64) NOT NULL COMMENT 'Key Id',
+  `name` varchar(120) NOT NULL COMMENT '名称',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  `prefix` varchar(32) NOT NULL DEFAULT '' COMMENT 'Key的前缀, 用于识别',
+  `key_hash` varchar(64) NOT NULL COMMENT 'Key的sha256摘要',
+  `scopes` varchar(512) NOT NULL DEFAULT '' COMMENT '授权范围, 逗号分隔',
+  `user_id` varchar(64) NOT NULL COMMENT '所属用户',
+  `username` varchar(120) NOT NULL DEFAULT '' COMMENT '用户名',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `expire_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '过期时间, 0表示永不过期',
+  `last_used_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '最后使用时间',
+  `revoke_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '吊销时间, 0表示未吊销',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_key_hash` (`key_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+ALTER TABLE `resource`
+  DROP INDEX `idx_namespace`,
+  DROP COLUMN `namespace`;
+
+DROP TABLE IF EXISTS `namespace`;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `namespace` (
+  `name` varchar(63) NOT NULL COMMENT '命名空间名称',
+  `display_name` varchar(120) NOT NULL DEFAULT '' COMMENT '显示名称',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  `members` varchar(4096) NOT NULL DEFAULT '' COMMENT '成员用户名, 逗号分隔',
+  `create_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `update_at` bigint(13) NOT NULL DEFAULT 0 COMMENT '更新时间',
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 已有的主机都归属到内置的default命名空间
+INSERT IGNORE INTO `namespace` (`name`, `display_name`, `description`) VALUES ('default', 'Default', '内置的命名空间');
+
+ALTER TABLE `resource`
+  ADD COLUMN `namespace` varchar(63) NOT NULL DEFAULT 'default' COMMENT '所属的命名空间' AFTER `id`,
+  ADD INDEX `idx_namespace` (`namespace`);
@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"context"
+	"net/http"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/response"
+)
+
+var (
+	// 不需要认证的接口, 格式: <method> <path>
+	anonymous = map[string]bool{
+		"POST /login": true,
+	}
+)
+
+// 认证中间件, 除了匿名接口外都需要携带有效的令牌或者API Key, 认证通过后把用户放入请求的上下文
+func (s *HTTPService) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if anonymous[r.Method+" "+r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			ctx context.Context
+			err error
+		)
+		if key := apikey.GetKeyFromHTTP(r); key != "" {
+			ctx, err = s.authenticateAPIKey(r.Context(), key)
+		} else {
+			ctx, err = s.authenticateToken(r.Context(), user.GetTokenFromHTTP(r))
+		}
+		if err != nil {
+			s.l.Debugf("authenticate %s %s failed, %s", r.Method, r.URL.Path, err)
+			response.Failed(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (s *HTTPService) authenticateToken(ctx context.Context, accessToken string) (context.Context, error) {
+	u, err := apps.User.ValidateToken(ctx, &user.ValidateTokenRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, err
+	}
+	return user.WithUser(ctx, u), nil
+}
+
+// 通过API Key访问时, 以Key所属用户的身份访问, 权限受Key的授权范围限制
+func (s *HTTPService) authenticateAPIKey(ctx context.Context, key string) (context.Context, error) {
+	k, err := apps.APIKey.ValidateAPIKey(ctx, &apikey.ValidateAPIKeyRequest{Key: key})
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := apps.User.DescribeUser(ctx, &user.DescribeUserRequest{Id: k.UserId})
+	if err != nil {
+		if exception.IsNotFoundError(err) {
+			return nil, exception.NewUnauthorized("owner of the api key not exists")
+		}
+		return nil, err
+	}
+	return apikey.WithAPIKey(user.WithUser(ctx, u), k), nil
+}
@@ -6,16 +6,22 @@ import (
 	"net/http"
 	"time"
 
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	accountAPI "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/account/http"
+	apikeyAPI "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey/http"
 	hostAPI "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/host/http"
+	namespaceAPI "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace/http"
+	syncAPI "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/syncer/http"
+	userAPI "xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user/http"
 	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
 
 	"github.com/infraboard/mcube/logger"
 	"github.com/infraboard/mcube/logger/zap"
-	"github.com/julienschmidt/httprouter"
 )
 
 func NewHTTPService() *HTTPService {
-	r := httprouter.New()
+	r := router.New()
 	return &HTTPService{
 		r: r,
 		l: zap.L().Named("HTTP Server"),
@@ -36,6 +42,8 @@ func NewHTTPService() *HTTPService {
 			IdleTimeout: 60 * time.Second,
 			// header 大小控制
 			MaxHeaderBytes: 1 << 20, // 1M
+			// 导出等耗时的路由需要单独放宽写超时
+			ConnContext: router.ConnContext,
 		},
 	}
 }
@@ -43,7 +51,7 @@ func NewHTTPService() *HTTPService {
 // HTTPService http服务
 type HTTPService struct {
 	// router, root router, 路由, method+path --> handler
-	r *httprouter.Router
+	r *router.Router
 	// 日志
 	l logger.Logger
 	// 配置
@@ -58,6 +66,28 @@ func (s *HTTPService) Start() error {
 	// host http api 服务模块, 初始化
 	hostAPI.API.Init()
 	hostAPI.API.Registry(s.r)
+	// 命名空间管理
+	namespaceAPI.API.Init()
+	namespaceAPI.API.Registry(s.r)
+	// 云厂商资源同步
+	syncAPI.API.Init()
+	syncAPI.API.Registry(s.r)
+	// 云账号管理, 只在MySQL存储时提供
+	if apps.Account != nil {
+		accountAPI.API.Init()
+		accountAPI.API.Registry(s.r)
+	}
+	// 用户登录
+	userAPI.API.Init()
+	userAPI.API.Registry(s.r)
+	// 自动化客户端使用的API Key
+	apikeyAPI.API.Init()
+	apikeyAPI.API.Registry(s.r)
+
+	// 开启认证后, 所有请求先经过认证中间件
+	if conf.C().Auth.Enable {
+		s.server.Handler = s.authenticate(s.r)
+	}
 
 	// 启动 HTTP服务
 	s.l.Infof("HTTP服务启动成功, 监听地址: %s", s.server.Addr)
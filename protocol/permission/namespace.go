@@ -0,0 +1,25 @@
+package permission
+
+import (
+	"net/http"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/namespace"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+
+	"github.com/infraboard/mcube/exception"
+)
+
+// 当前用户可以访问的命名空间, nil表示不限制
+// 认证关闭时请求中没有用户, 和管理员一样可以访问所有命名空间, 其他用户只能访问自己是成员的命名空间
+func AllowedNamespaces(r *http.Request) ([]string, error) {
+	u := user.FromContext(r.Context())
+	if u == nil || u.Role == user.RoleAdmin {
+		return nil, nil
+	}
+
+	if apps.Namespace == nil {
+		return nil, exception.NewInternalServerError("dependence namespace service is nil")
+	}
+	return apps.Namespace.ListMemberNamespace(r.Context(), namespace.NewListMemberNamespaceRequest(u.Username))
+}
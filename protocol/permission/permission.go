@@ -0,0 +1,45 @@
+package permission
+
+import (
+	"net/http"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/apikey"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/policy"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/apps/user"
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/conf"
+
+	"github.com/infraboard/mcube/exception"
+	"github.com/infraboard/mcube/http/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// 包装路由的处理函数, 调用前检查当前用户是否拥有权限
+// 认证关闭时请求中没有用户, 直接放行
+func Required(perm policy.Permission, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		u := user.FromContext(r.Context())
+		if u == nil {
+			if !conf.C().Auth.Enable {
+				next(w, r, ps)
+				return
+			}
+			response.Failed(w, exception.NewUnauthorized("authentication required"))
+			return
+		}
+
+		if apps.Policy == nil {
+			response.Failed(w, exception.NewInternalServerError("dependence policy service is nil"))
+			return
+		}
+		req := policy.NewCheckPermissionRequest(u, perm)
+		if k := apikey.FromContext(r.Context()); k != nil {
+			req.Scopes = k.Scopes
+		}
+		if err := apps.Policy.CheckPermission(r.Context(), req); err != nil {
+			response.Failed(w, err)
+			return
+		}
+		next(w, r, ps)
+	}
+}
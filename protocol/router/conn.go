@@ -0,0 +1,26 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+type connKey struct{}
+
+// 作为http.Server的ConnContext, 把连接保存到请求的context中, 用于单独调整某个路由的写超时
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// 修改当前请求的写超时, 服务端读取下一个请求时会重新设置, 不影响其他请求
+// Go 1.20 之后可以使用 http.ResponseController 代替
+func SetWriteDeadline(r *http.Request, deadline time.Time) error {
+	c, ok := r.Context().Value(connKey{}).(net.Conn)
+	if !ok {
+		return fmt.Errorf("request connection not found, server ConnContext not set")
+	}
+	return c.SetWriteDeadline(deadline)
+}
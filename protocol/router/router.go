@@ -0,0 +1,130 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// 对httprouter的简单包装
+// httprouter 不允许同一层级同时存在静态路径和路径参数, 比如 /hosts/stats 和 /hosts/:id,
+// 也会把 /hosts:batch 中的 : 当成路径参数, 所以不带路径参数的路由单独保存, 请求时优先精确匹配;
+// 带路径参数的路由之间冲突时, 比如 /hosts/:id 和 /hosts/by-instance/:vendor/:instance_id,
+// 冲突的路由注册到额外的httprouter中, 请求时选择路径参数最少的匹配结果
+func New() *Router {
+	return &Router{
+		Router: httprouter.New(),
+		static: map[string]map[string]httprouter.Handle{},
+	}
+}
+
+type Router struct {
+	*httprouter.Router
+
+	// method --> path --> handle
+	static map[string]map[string]httprouter.Handle
+	// 和已有路由冲突的带参数路由
+	extra []*httprouter.Router
+}
+
+func (r *Router) GET(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodGet, path, handle)
+}
+
+func (r *Router) POST(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPost, path, handle)
+}
+
+func (r *Router) PUT(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPut, path, handle)
+}
+
+func (r *Router) PATCH(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPatch, path, handle)
+}
+
+func (r *Router) DELETE(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodDelete, path, handle)
+}
+
+// 注册路由, 静态路径单独保存, 带路径参数的交给httprouter
+func (r *Router) Handle(method, path string, handle httprouter.Handle) {
+	if !isStatic(path) {
+		r.handleParam(method, path, handle)
+		return
+	}
+
+	if _, ok := r.static[method]; !ok {
+		r.static[method] = map[string]httprouter.Handle{}
+	}
+	if _, ok := r.static[method][path]; ok {
+		panic("a handle is already registered for path '" + path + "'")
+	}
+	r.static[method][path] = handle
+}
+
+// 依次尝试注册到各个httprouter, 都冲突时新建一个
+func (r *Router) handleParam(method, path string, handle httprouter.Handle) {
+	for _, router := range append([]*httprouter.Router{r.Router}, r.extra...) {
+		if tryHandle(router, method, path, handle) {
+			return
+		}
+	}
+
+	router := httprouter.New()
+	router.Handle(method, path, handle)
+	r.extra = append(r.extra, router)
+}
+
+// 只忽略路径冲突的错误, 重复注册等其他错误照常panic
+func tryHandle(router *httprouter.Router, method, path string, handle httprouter.Handle) (ok bool) {
+	defer func() {
+		if e := recover(); e != nil {
+			if msg, isStr := e.(string); isStr && strings.Contains(msg, "conflicts with") {
+				ok = false
+				return
+			}
+			panic(e)
+		}
+	}()
+	router.Handle(method, path, handle)
+	return true
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if handle, ok := r.static[req.Method][req.URL.Path]; ok {
+		handle(w, req, nil)
+		return
+	}
+
+	// 存在冲突路由时, 路径参数越少的路由越精确
+	if len(r.extra) > 0 {
+		var (
+			matched httprouter.Handle
+			params  httprouter.Params
+		)
+		for _, router := range append([]*httprouter.Router{r.Router}, r.extra...) {
+			handle, ps, _ := router.Lookup(req.Method, req.URL.Path)
+			if handle != nil && (matched == nil || len(ps) < len(params)) {
+				matched, params = handle, ps
+			}
+		}
+		if matched != nil {
+			matched(w, req, params)
+			return
+		}
+	}
+
+	r.Router.ServeHTTP(w, req)
+}
+
+// 路径中没有以 : 或者 * 开头的段, 就是静态路径
+func isStatic(path string) bool {
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/protocol/router"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticAndParamRoutes(t *testing.T) {
+	should := assert.New(t)
+
+	var hit string
+	handle := func(name string) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			hit = name + ps.ByName("id")
+		}
+	}
+
+	r := router.New()
+	r.POST("/hosts", handle("create"))
+	r.POST("/hosts:batch", handle("batch"))
+	r.POST("/hosts/:id/restore", handle("restore"))
+	r.GET("/hosts/stats", handle("stats"))
+	r.GET("/hosts/:id", handle("describe"))
+	r.PUT("/hosts/:id", handle("update"))
+	r.PUT("/hosts/by-instance/:vendor/:instance_id", handle("upsert"))
+
+	cases := map[string]string{
+		"POST /hosts":                          "create",
+		"POST /hosts:batch":                    "batch",
+		"POST /hosts/h1/restore":               "restoreh1",
+		"GET /hosts/stats":                     "stats",
+		"GET /hosts/h1":                        "describeh1",
+		"PUT /hosts/h1":                        "updateh1",
+		"PUT /hosts/by-instance/ALI_CLOUD/i-1": "upsert",
+	}
+	for req, want := range cases {
+		hit = ""
+		parts := strings.SplitN(req, " ", 2)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(parts[0], parts[1], nil))
+		should.Equal(want, hit, req)
+	}
+}
+
+func TestSetWriteDeadline(t *testing.T) {
+	should := assert.New(t)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/long" {
+			should.NoError(router.SetWriteDeadline(r, time.Now().Add(time.Second)))
+		}
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Config.ConnContext = router.ConnContext
+	srv.Start()
+	defer srv.Close()
+
+	// 超过服务端的写超时, 响应被中断
+	_, err := http.Get(srv.URL + "/short")
+	should.Error(err)
+
+	resp, err := http.Get(srv.URL + "/long")
+	if should.NoError(err) {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		should.NoError(err)
+		should.Equal("done", string(body))
+	}
+}
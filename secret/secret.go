@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// 加密后的数据前缀, 没有前缀的数据按明文处理, 兼容加密之前写入的数据
+	prefix = "enc:v1:"
+)
+
+// 使用App.Key派生的密钥做AES-256-GCM加密, 用于入库前加密敏感字段
+func NewCipher(key string) (*Cipher, error) {
+	if key == "" {
+		return nil, fmt.Errorf("app key required for encrypting sensitive data")
+	}
+
+	block, err := aes.NewCipher(derive(key, "aead"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead, nonceKey: derive(key, "nonce")}, nil
+}
+
+type Cipher struct {
+	aead cipher.AEAD
+	// 确定性加密时用于生成nonce
+	nonceKey []byte
+}
+
+// 从App.Key派生出不同用途的32字节密钥
+func derive(key, usage string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("restful-api/" + usage))
+	return mac.Sum(nil)
+}
+
+// 使用随机nonce加密, 相同的明文每次得到不同的密文, 空字符串不加密
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return c.seal(nonce, plain), nil
+}
+
+// 确定性加密, 相同的明文得到相同的密文, 用于需要等值查询的字段
+// 代价是泄露了哪些记录的值相同, 不需要查询的字段使用Encrypt
+func (c *Cipher) EncryptDeterministic(plain string) string {
+	if plain == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.nonceKey)
+	mac.Write([]byte(plain))
+	return c.seal(mac.Sum(nil)[:c.aead.NonceSize()], plain)
+}
+
+func (c *Cipher) seal(nonce []byte, plain string) string {
+	data := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(data)
+}
+
+// 解密, 没有加密前缀的数据原样返回
+func (c *Cipher) Decrypt(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted data error, %s", err)
+	}
+	if len(data) < c.aead.NonceSize() {
+		return "", fmt.Errorf("encrypted data too short")
+	}
+
+	size := c.aead.NonceSize()
+	plain, err := c.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt data error, the app key may be wrong, %s", err)
+	}
+	return string(plain), nil
+}
+
+// 是否是加密后的数据
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
@@ -0,0 +1,39 @@
+package secret_test
+
+import (
+	"testing"
+
+	"xiaosong372089396/learning-Restful-API-HTTP-Demo/secret"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCipher(t *testing.T) {
+	should := assert.New(t)
+
+	c, err := secret.NewCipher("test key")
+	if !should.NoError(err) {
+		return
+	}
+
+	enc, err := c.Encrypt("my-key-pair")
+	should.NoError(err)
+	should.True(secret.IsEncrypted(enc))
+	other, _ := c.Encrypt("my-key-pair")
+	should.NotEqual(enc, other)
+	plain, err := c.Decrypt(enc)
+	should.NoError(err)
+	should.Equal("my-key-pair", plain)
+
+	should.Equal(c.EncryptDeterministic("account"), c.EncryptDeterministic("account"))
+	should.Equal("", c.EncryptDeterministic(""))
+
+	// 加密之前写入的明文原样返回
+	plain, err = c.Decrypt("legacy")
+	should.NoError(err)
+	should.Equal("legacy", plain)
+
+	wrong, _ := secret.NewCipher("wrong key")
+	_, err = wrong.Decrypt(enc)
+	should.Error(err)
+}